@@ -0,0 +1,159 @@
+package bazelrc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Patterns matching the lines of `--announce_rc` output, after removing colours and timestamps.
+var (
+	ansiEscapePattern       = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	logTimestampPattern     = regexp.MustCompile(`^\([0-9:.]+\) `)
+	readingRcOptionsPattern = regexp.MustCompile(`^INFO: Reading rc options for '[^']+' from (.+):$`)
+	clientOptionsPattern    = regexp.MustCompile(`^INFO: Options provided by the client:$`)
+	announcedSectionPattern = regexp.MustCompile(`^\s+(?:Inherited )?'([^']+)' options: (.*)$`)
+	configDefinitionPattern = regexp.MustCompile(`^INFO: Found applicable config definition (\S+) in file (.+?): (.*)$`)
+	startupOptionsPattern   = regexp.MustCompile(`^INFO: Reading 'startup' options from (.+?): (.*)$`)
+)
+
+// ParseAnnounceRCOutput reconstructs the options Bazel read from bazelrc files from the output of an invocation with `--announce_rc`, e.g. as pasted into a bug report.
+// The options are returned in the same form as from parsing the files, so the two can be compared with DiffAnnouncedOptions.
+// Each option's Command is the section it was announced for (e.g. "build" or "build:ci"), and its position identifies the file it was read from (or "client" for options added by Bazel's client), but not the line, which Bazel doesn't announce.
+// Lines other than those announcing options are ignored, as are repeated announcements of the same section of the same file (e.g. from several invocations in one log).
+func ParseAnnounceRCOutput(knownFlagData *FlagData, output io.Reader) (*BazelrcContents, error) {
+	contents := newBazelrcContents()
+	type announcedSection struct {
+		file, command, args string
+	}
+	var announced []announcedSection
+	currentFile := ""
+	scanner := bufio.NewScanner(output)
+	scanner.Buffer(nil, 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := ansiEscapePattern.ReplaceAllString(strings.TrimRight(scanner.Text(), "\r"), "")
+		line = logTimestampPattern.ReplaceAllString(line, "")
+
+		var section announcedSection
+		if match := readingRcOptionsPattern.FindStringSubmatch(line); match != nil {
+			currentFile = match[1]
+			continue
+		} else if clientOptionsPattern.MatchString(line) {
+			currentFile = clientRcSource
+			continue
+		} else if match := announcedSectionPattern.FindStringSubmatch(line); match != nil && currentFile != "" {
+			section = announcedSection{file: currentFile, command: match[1], args: match[2]}
+		} else if match := configDefinitionPattern.FindStringSubmatch(line); match != nil {
+			section = announcedSection{file: match[2], command: match[1], args: match[3]}
+		} else if match := startupOptionsPattern.FindStringSubmatch(line); match != nil {
+			section = announcedSection{file: match[1], command: "startup", args: match[2]}
+		} else {
+			if !strings.HasPrefix(line, " ") {
+				currentFile = ""
+			}
+			continue
+		}
+		if slices.Contains(announced, section) {
+			continue
+		}
+		announced = append(announced, section)
+
+		options, err := parseAnnouncedOptions(knownFlagData, section.file, section.command, section.args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse --announce_rc output on line %d: %w", lineNumber, err)
+		}
+		for _, option := range options {
+			contents.addOption(option)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read --announce_rc output: %w", err)
+	}
+	return contents, nil
+}
+
+// parseAnnouncedOptions parses the options announced for command from file.
+func parseAnnouncedOptions(knownFlagData *FlagData, file string, command string, args string) ([]Option, error) {
+	start := Position{File: file, Column: 1, ArgIndex: -1}
+	tokens, err := tokenize(args, start)
+	if err != nil {
+		var tokenizeErr *tokenizeError
+		if errors.As(err, &tokenizeErr) {
+			return nil, &TokenizeError{Location: newSpanLocation([]string{file}, tokenizeErr.span), Err: errors.New(tokenizeErr.message)}
+		}
+		return nil, &TokenizeError{Location: newSpanLocation([]string{file}, Span{Start: start, End: start}), Err: err}
+	}
+	parser := NewBazelRcParser("", knownFlagData).parserForCommand(command)
+	importCallStack := []string{file}
+	var options []Option
+	var targets []string
+	var flagExpectingValue *pendingFlag
+	flagAliases := copyFlagAliases(knownFlagData)
+	if _, err := parser.parseTokenizedArgsOnSingleLineAfterCommand(tokens, &options, &targets, &flagExpectingValue, flagAliases, importCallStack); err != nil {
+		return nil, err
+	}
+	if flagExpectingValue != nil {
+		return nil, &MissingFlagValueError{Location: newSpanLocation(importCallStack, flagExpectingValue.token.Span), Flag: stripLeadingDashes(flagExpectingValue.nameWithLeadingDashes)}
+	}
+	if err := parser.normalizeOptionValues(options, importCallStack); err != nil {
+		return nil, err
+	}
+	for i := range options {
+		options[i].Command = command
+	}
+	return options, nil
+}
+
+// ProvenanceMismatch describes a section of a bazelrc file whose options differ between two sources, e.g. local parsing and `--announce_rc` output.
+type ProvenanceMismatch struct {
+	// File is the path of the bazelrc file.
+	File string
+	// Command is the section's command, including any config name (e.g. "build:ci").
+	Command string
+	// Want and Got are the options in the section, e.g. `--jobs=10`, in order.
+	Want []string
+	Got  []string
+}
+
+func (m ProvenanceMismatch) String() string {
+	return fmt.Sprintf("%s in %s: want %q, got %q", m.Command, m.File, m.Want, m.Got)
+}
+
+// DiffAnnouncedOptions compares the options Bazel announced (from ParseAnnounceRCOutput) with those found by parsing the same bazelrc files locally, returning the sections which differ.
+// Only sections which were announced are compared, as Bazel only announces the sections which apply to the invocation.
+// Options added by Bazel's client are ignored.
+func DiffAnnouncedOptions(local *BazelrcContents, announced *BazelrcContents) []ProvenanceMismatch {
+	type section struct {
+		file, command string
+	}
+	optionsBySection := func(contents *BazelrcContents) (map[section][]string, []section) {
+		bySection := make(map[section][]string)
+		var order []section
+		for _, option := range contents.Options() {
+			key := section{file: option.Span().Start.File, command: option.Command}
+			if _, ok := bySection[key]; !ok {
+				order = append(order, key)
+			}
+			bySection[key] = append(bySection[key], fmt.Sprintf("--%s=%s", option.Name, option.Value))
+		}
+		return bySection, order
+	}
+	localOptions, _ := optionsBySection(local)
+	announcedOptions, announcedOrder := optionsBySection(announced)
+
+	var mismatches []ProvenanceMismatch
+	for _, key := range announcedOrder {
+		if key.file == clientRcSource {
+			continue
+		}
+		if !slices.Equal(localOptions[key], announcedOptions[key]) {
+			mismatches = append(mismatches, ProvenanceMismatch{File: key.file, Command: key.command, Want: localOptions[key], Got: announcedOptions[key]})
+		}
+	}
+	return mismatches
+}
@@ -0,0 +1,72 @@
+package bazelrc
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/rules_go/go/runfiles"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAnnounceRCOutput(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags:   map[string]bool{"keep_going": true, "isatty": true},
+		StartupOptions: &FlagData{},
+	}
+	logPath, err := runfiles.Rlocation("bazelrc_parser_go/bazelrc/testdata/announce_rc.log")
+	require.NoError(t, err)
+	log, err := os.Open(logPath)
+	require.NoError(t, err)
+	defer log.Close()
+
+	announced, err := ParseAnnounceRCOutput(flagData, log)
+	require.NoError(t, err)
+	require.Equal(t, map[string]BazelFlagValues{
+		"startup":  {"output_base": []string{"/tmp/out"}},
+		"common":   {"isatty": []string{"1"}, "terminal_columns": []string{"80"}, "color": []string{"yes"}},
+		"build":    {"jobs": []string{"10"}, "keep_going": []string{"true"}},
+		"build:ci": {"jobs": []string{"20"}, "copt": []string{"-O2"}},
+	}, announced.entries)
+
+	var files []string
+	for _, option := range announced.Options() {
+		files = append(files, option.Span().Start.File)
+	}
+	require.Equal(t, []string{"/home/user/.bazelrc", "client", "client", "/workspace/.bazelrc", "/workspace/.bazelrc", "/workspace/.bazelrc", "/workspace/.bazelrc", "/workspace/.bazelrc"}, files)
+
+	local, err := NewBazelRcParser("", flagData).Parsefile(strings.NewReader(`startup --output_base=/tmp/out
+common --color=yes
+build --jobs 10 --keep_going
+build:ci --jobs=20 --copt=-O1
+test --test_output=errors
+`), "/workspace/.bazelrc")
+	require.NoError(t, err)
+	mismatches := DiffAnnouncedOptions(local, announced)
+	require.Equal(t, []ProvenanceMismatch{
+		{File: "/home/user/.bazelrc", Command: "startup", Got: []string{"--output_base=/tmp/out"}},
+		{File: "/workspace/.bazelrc", Command: "build:ci", Want: []string{"--jobs=20", "--copt=-O1"}, Got: []string{"--jobs=20", "--copt=-O2"}},
+	}, mismatches)
+	require.Equal(t, `build:ci in /workspace/.bazelrc: want ["--jobs=20" "--copt=-O1"], got ["--jobs=20" "--copt=-O2"]`, mismatches[1].String())
+}
+
+func TestParseAnnounceRCOutputErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		output  string
+		wantErr string
+	}{
+		"missing value": {
+			output:  "INFO: Reading rc options for 'build' from /workspace/.bazelrc:\n  'build' options: --jobs\n",
+			wantErr: "failed to parse --announce_rc output on line 2: failed to process /workspace/.bazelrc, value-requiring flag jobs didn't have value",
+		},
+		"unmatched quote": {
+			output:  "INFO: Found applicable config definition build:ci in file /workspace/.bazelrc: --copt='-O2\n",
+			wantErr: "failed to parse --announce_rc output on line 1: failed to process /workspace/.bazelrc, unable to split line: EOF found when expecting closing quote",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseAnnounceRCOutput(&FlagData{}, strings.NewReader(tc.output))
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
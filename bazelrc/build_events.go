@@ -0,0 +1,289 @@
+package bazelrc
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bazel-contrib/bazelrc-parser-go/bazel_protos/command_line"
+)
+
+// Field numbers of the BuildEvent payloads which are read, from build_event_stream.proto.
+const (
+	buildEventUnstructuredCommandLineField = 12
+	buildEventOptionsParsedField           = 13
+	buildEventStructuredCommandLineField   = 22
+)
+
+// BuildEventOptions holds the events describing an invocation's options from a Build Event Protocol file.
+type BuildEventOptions struct {
+	// UnstructuredCommandLine is the arguments of the UnstructuredCommandLine event, as received by the Bazel server, or nil if there was no such event.
+	UnstructuredCommandLine []string
+	// OptionsParsed is the OptionsParsed event, or nil if there was no such event.
+	OptionsParsed *OptionsParsed
+	// StructuredCommandLines holds each StructuredCommandLine event (usually labelled "original" and "canonical"), in the order they appeared.
+	StructuredCommandLines []*command_line.CommandLine
+}
+
+// OptionsParsed is Bazel's report of the options it parsed, from the OptionsParsed build event.
+type OptionsParsed struct {
+	// StartupOptions are the effective startup options, in canonical form.
+	StartupOptions []string `json:"startupOptions"`
+	// ExplicitStartupOptions are the startup options which were set explicitly, in bazelrc files or on the command line.
+	ExplicitStartupOptions []string `json:"explicitStartupOptions"`
+	// CmdLine are the effective command options, in canonical form, with `--config` and expansion flags expanded.
+	CmdLine []string `json:"cmdLine"`
+	// ExplicitCmdLine are the command options which were set explicitly, in bazelrc files or on the command line.
+	ExplicitCmdLine []string `json:"explicitCmdLine"`
+	// ToolTag is the value of `--tool_tag`.
+	ToolTag string `json:"toolTag"`
+}
+
+// ReadBuildEventFile reads the options of an invocation from a Build Event Protocol file, as written by `--build_event_json_file` (if path ends in `.json`) or `--build_event_binary_file`.
+func ReadBuildEventFile(path string) (*BuildEventOptions, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open build event file: %w", err)
+	}
+	defer file.Close()
+	if strings.HasSuffix(path, ".json") {
+		return ReadBuildEventJSON(file)
+	}
+	return ReadBuildEventBinary(file)
+}
+
+// buildEventJSON holds the payloads of a BuildEvent in JSON form which are read.
+type buildEventJSON struct {
+	UnstructuredCommandLine *struct {
+		Args []string `json:"args"`
+	} `json:"unstructuredCommandLine"`
+	OptionsParsed         *OptionsParsed  `json:"optionsParsed"`
+	StructuredCommandLine json.RawMessage `json:"structuredCommandLine"`
+}
+
+// ReadBuildEventJSON reads the options of an invocation from build events in JSON form, as written by `--build_event_json_file`.
+// Events other than UnstructuredCommandLine, OptionsParsed and StructuredCommandLine are ignored.
+func ReadBuildEventJSON(r io.Reader) (*BuildEventOptions, error) {
+	options := &BuildEventOptions{}
+	decoder := json.NewDecoder(r)
+	for eventIndex := 0; ; eventIndex++ {
+		var event buildEventJSON
+		if err := decoder.Decode(&event); errors.Is(err, io.EOF) {
+			return options, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read build event %d: %w", eventIndex, err)
+		}
+		if event.UnstructuredCommandLine != nil {
+			options.UnstructuredCommandLine = event.UnstructuredCommandLine.Args
+		}
+		if event.OptionsParsed != nil {
+			options.OptionsParsed = event.OptionsParsed
+		}
+		if event.StructuredCommandLine != nil {
+			commandLine := &command_line.CommandLine{}
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(event.StructuredCommandLine, commandLine); err != nil {
+				return nil, fmt.Errorf("failed to read structured command line from build event %d: %w", eventIndex, err)
+			}
+			options.StructuredCommandLines = append(options.StructuredCommandLines, commandLine)
+		}
+	}
+}
+
+// ReadBuildEventBinary reads the options of an invocation from length-delimited binary build events, as written by `--build_event_binary_file`.
+// Events other than UnstructuredCommandLine, OptionsParsed and StructuredCommandLine are ignored.
+func ReadBuildEventBinary(r io.Reader) (*BuildEventOptions, error) {
+	reader := bufio.NewReader(r)
+	options := &BuildEventOptions{}
+	for eventIndex := 0; ; eventIndex++ {
+		length, err := binary.ReadUvarint(reader)
+		if errors.Is(err, io.EOF) {
+			return options, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read length of build event %d: %w", eventIndex, err)
+		}
+		event := make([]byte, length)
+		if _, err := io.ReadFull(reader, event); err != nil {
+			return nil, fmt.Errorf("failed to read build event %d: %w", eventIndex, err)
+		}
+		if err := options.addBinaryEvent(event); err != nil {
+			return nil, fmt.Errorf("failed to read build event %d: %w", eventIndex, err)
+		}
+	}
+}
+
+// addBinaryEvent records the payload of the serialized BuildEvent event, if it's one of the events describing options.
+func (o *BuildEventOptions) addBinaryEvent(event []byte) error {
+	return forEachField(event, func(number protowire.Number, value []byte) error {
+		switch number {
+		case buildEventUnstructuredCommandLineField:
+			o.UnstructuredCommandLine = []string{}
+			return forEachField(value, func(number protowire.Number, value []byte) error {
+				if number == 1 {
+					o.UnstructuredCommandLine = append(o.UnstructuredCommandLine, string(value))
+				}
+				return nil
+			})
+		case buildEventOptionsParsedField:
+			optionsParsed := &OptionsParsed{}
+			o.OptionsParsed = optionsParsed
+			return forEachField(value, func(number protowire.Number, value []byte) error {
+				switch number {
+				case 1:
+					optionsParsed.StartupOptions = append(optionsParsed.StartupOptions, string(value))
+				case 2:
+					optionsParsed.ExplicitStartupOptions = append(optionsParsed.ExplicitStartupOptions, string(value))
+				case 3:
+					optionsParsed.CmdLine = append(optionsParsed.CmdLine, string(value))
+				case 4:
+					optionsParsed.ExplicitCmdLine = append(optionsParsed.ExplicitCmdLine, string(value))
+				case 6:
+					optionsParsed.ToolTag = string(value)
+				}
+				return nil
+			})
+		case buildEventStructuredCommandLineField:
+			commandLine := &command_line.CommandLine{}
+			if err := proto.Unmarshal(value, commandLine); err != nil {
+				return fmt.Errorf("failed to read structured command line: %w", err)
+			}
+			o.StructuredCommandLines = append(o.StructuredCommandLines, commandLine)
+		}
+		return nil
+	})
+}
+
+// forEachField calls f with the number and contents of each length-delimited field in the serialized message, skipping other fields.
+func forEachField(message []byte, f func(number protowire.Number, value []byte) error) error {
+	for len(message) > 0 {
+		number, fieldType, n := protowire.ConsumeTag(message)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		message = message[n:]
+		if fieldType != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(number, fieldType, message)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			message = message[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(message)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		message = message[n:]
+		if err := f(number, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StructuredCommandLine returns the StructuredCommandLine event with the given label (e.g. "canonical"), or nil if there is none.
+func (o *BuildEventOptions) StructuredCommandLine(label string) *command_line.CommandLine {
+	for _, commandLine := range o.StructuredCommandLines {
+		if commandLine.GetCommandLineLabel() == label {
+			return commandLine
+		}
+	}
+	return nil
+}
+
+// CommandLineArgs parses the UnstructuredCommandLine event, i.e. the command line as received by the Bazel server, skipping any startup options and the command.
+func (o *BuildEventOptions) CommandLineArgs(knownFlagData *FlagData) (*CommandLineArgsAfterCommand, error) {
+	if o.UnstructuredCommandLine == nil {
+		return nil, errors.New("no UnstructuredCommandLine build event")
+	}
+	startupArgs, err := ParseCommandLineStartupArgs(knownFlagData, o.UnstructuredCommandLine)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCommandLineArgsAfterCommand(knownFlagData, startupArgs.ArgsAfterCommand)
+}
+
+// EffectiveFlagValues parses the effective command options from the OptionsParsed event.
+func (o *BuildEventOptions) EffectiveFlagValues(knownFlagData *FlagData) (BazelFlagValues, error) {
+	if o.OptionsParsed == nil {
+		return nil, errors.New("no OptionsParsed build event")
+	}
+	parsed, err := ParseCommandLineArgsAfterCommand(knownFlagData, o.OptionsParsed.CmdLine)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.BazelFlags, nil
+}
+
+// FlagValuesFromCommandLine returns the values of the options in the section of commandLine with the given label (e.g. "command options").
+func FlagValuesFromCommandLine(commandLine *command_line.CommandLine, sectionLabel string) BazelFlagValues {
+	values := make(BazelFlagValues)
+	for _, section := range commandLine.GetSections() {
+		if section.GetSectionLabel() != sectionLabel {
+			continue
+		}
+		for _, option := range section.GetOptionList().GetOption() {
+			values[option.GetOptionName()] = append(values[option.GetOptionName()], option.GetOptionValue())
+		}
+	}
+	return values
+}
+
+// FlagValueMismatch describes a flag whose effective values differ between two sets of flag values.
+type FlagValueMismatch struct {
+	// Flag is the name of the flag, without leading dashes.
+	Flag string
+	// Want is the expected effective values, which is empty if the flag wasn't expected to be set.
+	Want []string
+	// Got is the actual effective values, which is empty if the flag wasn't set.
+	Got []string
+}
+
+func (m FlagValueMismatch) String() string {
+	return fmt.Sprintf("--%s: want %q, got %q", m.Flag, m.Want, m.Got)
+}
+
+// CompareFlagValues compares the effective values of each flag in want and got, e.g. as resolved by this library and as reported by Bazel, returning the mismatches sorted by flag.
+// Only the last value of each flag is compared, unless the flag accumulates values according to knownFlagData.AllowsMultiple.
+// Values are compared after normalizing them according to knownFlagData, so e.g. `1` matches `true` for boolean flags.
+func CompareFlagValues(knownFlagData *FlagData, want BazelFlagValues, got BazelFlagValues) []FlagValueMismatch {
+	flags := make(map[string]bool)
+	for flag := range want {
+		flags[flag] = true
+	}
+	for flag := range got {
+		flags[flag] = true
+	}
+	var mismatches []FlagValueMismatch
+	for flag := range flags {
+		wantValues := effectiveValues(knownFlagData, flag, want[flag])
+		gotValues := effectiveValues(knownFlagData, flag, got[flag])
+		if !slices.Equal(wantValues, gotValues) {
+			mismatches = append(mismatches, FlagValueMismatch{Flag: flag, Want: wantValues, Got: gotValues})
+		}
+	}
+	sort.Slice(mismatches, func(i, j int) bool { return mismatches[i].Flag < mismatches[j].Flag })
+	return mismatches
+}
+
+// effectiveValues returns the normalized values of flag which take effect, given that it was set to values in order.
+func effectiveValues(knownFlagData *FlagData, flag string, values []string) []string {
+	if len(values) > 1 && (knownFlagData == nil || !knownFlagData.AllowsMultiple[flag]) {
+		values = values[len(values)-1:]
+	}
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		normalized = append(normalized, knownFlagData.comparableValue(flag, value))
+	}
+	return normalized
+}
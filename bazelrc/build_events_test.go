@@ -0,0 +1,123 @@
+package bazelrc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/rules_go/go/runfiles"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestReadBuildEventFile(t *testing.T) {
+	jsonPath, err := runfiles.Rlocation("bazelrc_parser_go/bazelrc/testdata/build_events.json")
+	require.NoError(t, err)
+	fromJSON, err := ReadBuildEventFile(jsonPath)
+	require.NoError(t, err)
+
+	// Encode the same events as length-delimited binary BuildEvents.
+	var binaryEvents bytes.Buffer
+	writeEvent := func(field protowire.Number, payload []byte) {
+		event := protowire.AppendTag(nil, 1, protowire.BytesType)
+		event = protowire.AppendBytes(event, nil)
+		event = protowire.AppendTag(event, 20, protowire.VarintType)
+		event = protowire.AppendVarint(event, 0)
+		event = protowire.AppendTag(event, field, protowire.BytesType)
+		event = protowire.AppendBytes(event, payload)
+		binaryEvents.Write(protowire.AppendVarint(nil, uint64(len(event))))
+		binaryEvents.Write(event)
+	}
+	appendStrings := func(message []byte, field protowire.Number, values ...string) []byte {
+		for _, value := range values {
+			message = protowire.AppendTag(message, field, protowire.BytesType)
+			message = protowire.AppendString(message, value)
+		}
+		return message
+	}
+	writeEvent(5, appendStrings(nil, 1, "2b7c5a3e-0d3f-4c8e-9a43-1f5e0b9d2c11"))
+	writeEvent(buildEventUnstructuredCommandLineField, appendStrings(nil, 1, fromJSON.UnstructuredCommandLine...))
+	for _, commandLine := range fromJSON.StructuredCommandLines {
+		serialized, err := proto.Marshal(commandLine)
+		require.NoError(t, err)
+		writeEvent(buildEventStructuredCommandLineField, serialized)
+	}
+	optionsParsed := appendStrings(nil, 1, fromJSON.OptionsParsed.StartupOptions...)
+	optionsParsed = appendStrings(optionsParsed, 3, fromJSON.OptionsParsed.CmdLine...)
+	optionsParsed = appendStrings(optionsParsed, 4, fromJSON.OptionsParsed.ExplicitCmdLine...)
+	optionsParsed = appendStrings(optionsParsed, 6, fromJSON.OptionsParsed.ToolTag)
+	writeEvent(buildEventOptionsParsedField, optionsParsed)
+
+	testDir, err := os.MkdirTemp("", "build_events")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	binaryPath := filepath.Join(testDir, "build_events.bin")
+	require.NoError(t, os.WriteFile(binaryPath, binaryEvents.Bytes(), 0o644))
+	fromBinary, err := ReadBuildEventFile(binaryPath)
+	require.NoError(t, err)
+
+	flagData := &FlagData{BooleanFlags: map[string]bool{"keep_going": true}}
+	for name, options := range map[string]*BuildEventOptions{"json": fromJSON, "binary": fromBinary} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, []string{"build", "--config=ci", "--jobs=5", "//foo/..."}, options.UnstructuredCommandLine)
+			require.Equal(t, []string{"--config=ci", "--jobs=5"}, options.OptionsParsed.ExplicitCmdLine)
+			require.Equal(t, "ci-runner", options.OptionsParsed.ToolTag)
+			require.Len(t, options.StructuredCommandLines, 2)
+			require.Nil(t, options.StructuredCommandLine("missing"))
+
+			commandLineArgs, err := options.CommandLineArgs(flagData)
+			require.NoError(t, err)
+			require.Equal(t, []string{"//foo/..."}, commandLineArgs.Targets)
+			require.Equal(t, BazelFlagValues{"config": []string{"ci"}, "jobs": []string{"5"}}, commandLineArgs.BazelFlags)
+
+			effective, err := options.EffectiveFlagValues(flagData)
+			require.NoError(t, err)
+			require.Equal(t, BazelFlagValues{"keep_going": []string{"true"}, "jobs": []string{"10", "5"}}, effective)
+
+			canonical := FlagValuesFromCommandLine(options.StructuredCommandLine("canonical"), "command options")
+			require.Equal(t, BazelFlagValues{"keep_going": []string{"1"}, "jobs": []string{"5"}}, canonical)
+			require.Empty(t, CompareFlagValues(flagData, effective, canonical))
+		})
+	}
+}
+
+func TestReadBuildEventErrors(t *testing.T) {
+	_, err := ReadBuildEventJSON(strings.NewReader(`{"id":{}}` + "\n" + `{"id":`))
+	require.EqualError(t, err, "failed to read build event 1: unexpected EOF")
+
+	_, err = ReadBuildEventBinary(bytes.NewReader([]byte{0x05, 0x0a}))
+	require.EqualError(t, err, "failed to read build event 0: unexpected EOF")
+
+	_, err = (&BuildEventOptions{}).CommandLineArgs(nil)
+	require.EqualError(t, err, "no UnstructuredCommandLine build event")
+}
+
+func TestCompareFlagValues(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags:   map[string]bool{"keep_going": true},
+		AllowsMultiple: map[string]bool{"copt": true},
+	}
+	mismatches := CompareFlagValues(flagData,
+		BazelFlagValues{
+			"jobs":       []string{"10", "20"},
+			"keep_going": []string{"yes"},
+			"copt":       []string{"-O1", "-O2"},
+			"color":      []string{"yes"},
+		},
+		BazelFlagValues{
+			"jobs":        []string{"20"},
+			"keep_going":  []string{"1"},
+			"copt":        []string{"-O2"},
+			"test_output": []string{"errors"},
+		},
+	)
+	require.Equal(t, []FlagValueMismatch{
+		{Flag: "color", Want: []string{"yes"}, Got: []string{}},
+		{Flag: "copt", Want: []string{"-O1", "-O2"}, Got: []string{"-O2"}},
+		{Flag: "test_output", Want: []string{}, Got: []string{"errors"}},
+	}, mismatches)
+	require.Equal(t, `--copt: want ["-O1" "-O2"], got ["-O2"]`, mismatches[1].String())
+}
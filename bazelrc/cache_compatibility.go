@@ -0,0 +1,56 @@
+package bazelrc
+
+// localOnlyFlags lists flags which only configure the local machine, so don't change action keys whatever their effect tags say
+// (some of them are tagged `affects_outputs` or `unknown`, as they change where outputs are stored or fetched from).
+var localOnlyFlags = map[string]bool{
+	"disk_cache":                        true,
+	"repository_cache":                  true,
+	"distdir":                           true,
+	"jobs":                              true,
+	"loading_phase_threads":             true,
+	"local_cpu_resources":               true,
+	"local_ram_resources":               true,
+	"local_resources":                   true,
+	"local_test_jobs":                   true,
+	"remote_local_fallback":             true,
+	"remote_timeout":                    true,
+	"remote_max_connections":            true,
+	"remote_retries":                    true,
+	"symlink_prefix":                    true,
+	"experimental_convenience_symlinks": true,
+}
+
+// RemoteCacheCompatibility describes whether two invocations can share remote cache entries, i.e. whether their actions have the same keys.
+type RemoteCacheCompatibility struct {
+	// Incompatible lists the differences in options which change action keys (those whose Impact is ImpactChangesOutputs or ImpactInvalidatesAnalysisCache), sorted by flag.
+	Incompatible []ClassifiedDifference
+	// Unknown lists the differences in options whose Impact is unknown, which may or may not change action keys, sorted by flag.
+	Unknown []ClassifiedDifference
+	// Ignored lists the other differences, which don't change action keys (e.g. `--jobs` or `--disk_cache`), sorted by flag.
+	Ignored []ClassifiedDifference
+}
+
+// Compatible returns whether no options are known to differ in ways which change action keys.
+func (c *RemoteCacheCompatibility) Compatible() bool {
+	return len(c.Incompatible) == 0
+}
+
+// CheckRemoteCacheCompatibility compares the effective options of two invocations (e.g. the Values of two ResolvedInvocations, or from BuildEventOptions.EffectiveFlagValues),
+// to find the differences which mean they won't share remote cache entries.
+// Flags which only configure the local machine, like `--jobs` or `--disk_cache`, are ignored.
+func CheckRemoteCacheCompatibility(knownFlagData *FlagData, a BazelFlagValues, b BazelFlagValues) *RemoteCacheCompatibility {
+	compatibility := &RemoteCacheCompatibility{}
+	for _, difference := range ClassifyDifferences(knownFlagData, a, b) {
+		switch {
+		case localOnlyFlags[difference.Flag]:
+			compatibility.Ignored = append(compatibility.Ignored, difference)
+		case difference.Impact == ImpactChangesOutputs || difference.Impact == ImpactInvalidatesAnalysisCache:
+			compatibility.Incompatible = append(compatibility.Incompatible, difference)
+		case difference.Impact == ImpactUnknown:
+			compatibility.Unknown = append(compatibility.Unknown, difference)
+		default:
+			compatibility.Ignored = append(compatibility.Ignored, difference)
+		}
+	}
+	return compatibility
+}
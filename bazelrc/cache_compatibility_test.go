@@ -0,0 +1,57 @@
+package bazelrc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRemoteCacheCompatibility(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags:      map[string]bool{"keep_going": true},
+		FlagAbbreviations: map[string]string{"c": "compilation_mode"},
+		EffectTags: map[string][]string{
+			"jobs":             {"host_machine_resource_optimizations", "execution"},
+			"keep_going":       {"eagerness_to_exit"},
+			"compilation_mode": {"affects_outputs", "action_command_lines"},
+			"disk_cache":       {"unknown"},
+			"color":            {"terminal_output"},
+			"platforms":        {"affects_outputs", "changes_inputs", "loading_and_analysis"},
+		},
+	}
+	contents, err := NewBazelRcParser("", flagData).Parsefile(strings.NewReader(`build --jobs=10 --disk_cache=~/.cache/bazel-disk
+build:ci --jobs=100 --disk_cache= --keep_going --color=no
+build:opt -c opt
+`), "/sample/bazelrc")
+	require.NoError(t, err)
+	resolver := NewResolver(contents, flagData)
+	values := func(args ...string) BazelFlagValues {
+		parsed, err := ParseCommandLineArgsAfterCommand(flagData, args)
+		require.NoError(t, err)
+		resolved, err := resolver.Resolve("build", parsed.Options)
+		require.NoError(t, err)
+		return resolved.Values()
+	}
+
+	compatibility := CheckRemoteCacheCompatibility(flagData, values("--remote_header=x"), values("--config=ci", "--unknown_flag=1"))
+	require.True(t, compatibility.Compatible())
+	require.Empty(t, compatibility.Incompatible)
+	var ignored []string
+	for _, difference := range compatibility.Ignored {
+		ignored = append(ignored, difference.Flag)
+	}
+	require.Equal(t, []string{"color", "disk_cache", "jobs", "keep_going"}, ignored)
+	require.Equal(t, []ClassifiedDifference{
+		{FlagValueMismatch: FlagValueMismatch{Flag: "remote_header", Want: []string{"x"}, Got: []string{}}, Impact: ImpactUnknown},
+		{FlagValueMismatch: FlagValueMismatch{Flag: "unknown_flag", Want: []string{}, Got: []string{"1"}}, Impact: ImpactUnknown},
+	}, compatibility.Unknown)
+
+	compatibility = CheckRemoteCacheCompatibility(flagData, values("--config=ci"), values("--config=opt", "--platforms=//:linux", "--//pkg:flag=on"))
+	require.False(t, compatibility.Compatible())
+	require.Equal(t, []ClassifiedDifference{
+		{FlagValueMismatch: FlagValueMismatch{Flag: "//pkg:flag", Want: []string{}, Got: []string{"on"}}, Impact: ImpactInvalidatesAnalysisCache},
+		{FlagValueMismatch: FlagValueMismatch{Flag: "compilation_mode", Want: []string{}, Got: []string{"opt"}}, Impact: ImpactChangesOutputs},
+		{FlagValueMismatch: FlagValueMismatch{Flag: "platforms", Want: []string{}, Got: []string{"//:linux"}}, Impact: ImpactInvalidatesAnalysisCache},
+	}, compatibility.Incompatible)
+}
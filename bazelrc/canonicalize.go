@@ -0,0 +1,66 @@
+package bazelrc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CanonicalFlags is the canonical form of the options of an invocation, like the output of `bazel canonicalize-flags`.
+type CanonicalFlags struct {
+	// Command is the command the options are for, e.g. "test".
+	Command string `json:"command"`
+	// Options are the effective options, each in the form `--name=value`, in the order they're applied.
+	// Abbreviations and `--no` forms are expanded, `--config` and expansion flags are replaced by the options they expand to,
+	// and options overridden by later options for the same flag are removed, unless the flag accumulates values.
+	Options []string `json:"options"`
+	// Targets are the arguments which aren't options, e.g. `//foo/...`.
+	Targets []string `json:"targets"`
+	// ExecutableArgs are the arguments after a standalone `--`, e.g. for `bazel run`.
+	ExecutableArgs []string `json:"executableArgs"`
+	// Sources holds the option each of Options came from, at the same index.
+	Sources []ResolvedOption `json:"-"`
+}
+
+// JSON returns the JSON form of f, which (unlike the output of `bazel canonicalize-flags`) preserves values containing newlines.
+func (f *CanonicalFlags) JSON() ([]byte, error) {
+	return json.MarshalIndent(f, "", "  ")
+}
+
+// Canonicalize computes the canonical form of the options of an invocation of command, with the arguments args following the command (e.g. `["--config=ci", "//foo/..."]`).
+// Unlike `bazel canonicalize-flags`, it doesn't need a Bazel server, and accepts targets, which are returned separately.
+// Boolean values are spelled `1` or `0`, as Bazel spells them in canonical form.
+func (r *Resolver) Canonicalize(command string, args []string) (*CanonicalFlags, error) {
+	parsed, err := ParseCommandLineArgsAfterCommand(r.knownFlagData, args)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := r.Resolve(command, parsed.Options)
+	if err != nil {
+		return nil, err
+	}
+	canonical := &CanonicalFlags{
+		Command:        command,
+		Options:        []string{},
+		Targets:        append([]string{}, parsed.Targets...),
+		ExecutableArgs: append([]string{}, parsed.ExecutableArgs...),
+	}
+	for _, option := range canonicalizeOptions(r.knownFlagData, resolved.Options) {
+		canonical.Options = append(canonical.Options, fmt.Sprintf("--%s=%s", option.Name, canonicalValue(r.knownFlagData, option.Option)))
+		canonical.Sources = append(canonical.Sources, option)
+	}
+	return canonical, nil
+}
+
+// canonicalValue returns the value of option as Bazel spells it in canonical form.
+// Flags which may be given without a value but whose type isn't known are assumed to be booleans, so are spelled 1 or 0 if their value is `true` or `false`.
+func canonicalValue(knownFlagData *FlagData, option Option) string {
+	if knownFlagData.ValueType(option.Name) == ValueTypeBoolean || knownFlagData.isUntypedBooleanFlag(option.Name) {
+		switch option.Value {
+		case "true":
+			return "1"
+		case "false":
+			return "0"
+		}
+	}
+	return option.Value
+}
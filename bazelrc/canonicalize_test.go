@@ -0,0 +1,160 @@
+package bazelrc
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/rules_go/go/runfiles"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags:      map[string]bool{"keep_going": true},
+		FlagAbbreviations: map[string]string{"k": "keep_going"},
+		AllowsMultiple:    map[string]bool{"copt": true},
+	}
+	contents, err := NewBazelRcParser("", flagData).Parsefile(strings.NewReader(`build --jobs=10
+build:ci --jobs=20 --copt=-O2
+`), "/sample/bazelrc")
+	require.NoError(t, err)
+
+	canonical, err := NewResolver(contents, flagData).Canonicalize("run", []string{"--config=ci", "-k", "//foo:bar", "--jobs", "5", "--copt=-DMESSAGE=a\nb", "--", "--flag", "arg"})
+	require.NoError(t, err)
+	require.Equal(t, "run", canonical.Command)
+	require.Equal(t, []string{"--copt=-O2", "--keep_going=1", "--jobs=5", "--copt=-DMESSAGE=a\nb"}, canonical.Options)
+	require.Equal(t, []string{"//foo:bar"}, canonical.Targets)
+	require.Equal(t, []string{"--flag", "arg"}, canonical.ExecutableArgs)
+	require.Len(t, canonical.Sources, 4)
+	require.Equal(t, 2, canonical.Sources[0].Span().Start.Line)
+	require.Equal(t, "ci", canonical.Sources[0].Via[0].Value)
+	require.Equal(t, 3, canonical.Sources[2].Span().Start.ArgIndex)
+
+	encoded, err := canonical.JSON()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"command": "run",
+		"options": ["--copt=-O2", "--keep_going=1", "--jobs=5", "--copt=-DMESSAGE=a\nb"],
+		"targets": ["//foo:bar"],
+		"executableArgs": ["--flag", "arg"]
+	}`, string(encoded))
+
+	empty, err := NewResolver(contents, flagData).Canonicalize("build", nil)
+	require.NoError(t, err)
+	encoded, err = empty.JSON()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"command": "build", "options": ["--jobs=10"], "targets": [], "executableArgs": []}`, string(encoded))
+
+	_, err = NewResolver(contents, flagData).Canonicalize("build", []string{"--config=missing"})
+	require.EqualError(t, err, "config value 'missing' is not defined in any .rc file")
+}
+
+// TestCanonicalizeGolden checks Canonicalize against the hand-written cases in testdata/canonicalize/golden.txt, which weren't recorded from Bazel.
+func TestCanonicalizeGolden(t *testing.T) {
+	rlocation := func(path string) string {
+		location, err := runfiles.Rlocation("bazelrc_parser_go/bazelrc/testdata/" + path)
+		require.NoError(t, err)
+		return location
+	}
+	flagData, err := GetFlagDataFromHelpOutputFiles(rlocation("help_build_long.txt"))
+	require.NoError(t, err)
+	contents, err := NewBazelRcParser("", flagData).ParsePath(rlocation("canonicalize/bazelrc"))
+	require.NoError(t, err)
+	resolver := NewResolver(contents, flagData)
+
+	golden, err := os.Open(rlocation("canonicalize/golden.txt"))
+	require.NoError(t, err)
+	defer golden.Close()
+	type goldenCase struct {
+		command string
+		args    []string
+		want    []string
+	}
+	var cases []*goldenCase
+	scanner := bufio.NewScanner(golden)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "$ "):
+			fields := strings.Fields(line)
+			require.Equal(t, []string{"$", "bazel", "canonicalize-flags"}, fields[:3])
+			require.True(t, strings.HasPrefix(fields[3], "--for_command="))
+			require.Equal(t, "--", fields[4])
+			cases = append(cases, &goldenCase{command: strings.TrimPrefix(fields[3], "--for_command="), args: fields[5:], want: []string{}})
+		default:
+			require.NotEmpty(t, cases, "canonical option before the first command line: %s", line)
+			cases[len(cases)-1].want = append(cases[len(cases)-1].want, line)
+		}
+	}
+	require.NoError(t, scanner.Err())
+	require.NotEmpty(t, cases)
+
+	for _, tc := range cases {
+		t.Run(strings.Join(append([]string{tc.command}, tc.args...), " "), func(t *testing.T) {
+			canonical, err := resolver.Canonicalize(tc.command, tc.args)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, canonical.Options)
+		})
+	}
+}
+
+// TestCanonicalizeMatchesBazel checks Canonicalize against the output of `bazel canonicalize-flags` for command lines which need no bazelrc files,
+// using flag data from the same Bazel. It runs the bazel binary named by $BAZELRC_PARSER_TEST_BAZEL in an empty module,
+// e.g. `BAZELRC_PARSER_TEST_BAZEL=$(which bazel) go test ./bazelrc -run TestCanonicalizeMatchesBazel -v`, which also logs Bazel's output; it is skipped if that isn't set.
+func TestCanonicalizeMatchesBazel(t *testing.T) {
+	bazel := os.Getenv("BAZELRC_PARSER_TEST_BAZEL")
+	if bazel == "" {
+		t.Skip("BAZELRC_PARSER_TEST_BAZEL isn't set")
+	}
+	workspace := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "MODULE.bazel"), nil, 0o644))
+	bazelCommand := func(args ...string) *exec.Cmd {
+		command := exec.Command(bazel, append([]string{"--ignore_all_rc_files"}, args...)...)
+		command.Dir = workspace
+		return command
+	}
+	t.Cleanup(func() {
+		_ = bazelCommand("shutdown").Run()
+	})
+
+	flagData, err := GetFlagDataFromBazel(bazelCommand())
+	require.NoError(t, err)
+	contents, err := NewBazelRcParser(workspace, flagData).Parsefile(strings.NewReader(""), filepath.Join(workspace, ".bazelrc"))
+	require.NoError(t, err)
+	resolver := NewResolver(contents, flagData)
+
+	for _, tc := range []struct {
+		command string
+		args    []string
+	}{
+		{command: "build", args: []string{"--jobs", "5", "--nokeep_going"}},
+		{command: "build", args: []string{"-k", "-c", "dbg", "--copt=-O1", "--copt=-O3"}},
+		{command: "build", args: []string{"--jobs=10", "--keep_going", "--jobs=20"}},
+		{command: "test", args: []string{"--test_output=errors", "--test_tag_filters=-flaky", "--compilation_mode=opt"}},
+		{command: "build", args: []string{"--noincompatible_strict_action_env", "--define=a=1", "--incompatible_strict_action_env", "--define=a=2"}},
+	} {
+		t.Run(strings.Join(append([]string{tc.command}, tc.args...), " "), func(t *testing.T) {
+			command := bazelCommand(append([]string{"canonicalize-flags", "--for_command=" + tc.command, "--"}, tc.args...)...)
+			var stderr bytes.Buffer
+			command.Stderr = &stderr
+			output, err := command.Output()
+			require.NoError(t, err, stderr.String())
+			t.Logf("$ bazel canonicalize-flags --for_command=%s -- %s\n%s", tc.command, strings.Join(tc.args, " "), output)
+			// Bazel prints each canonical option on its own line.
+			want := []string{}
+			if trimmed := strings.TrimSuffix(string(output), "\n"); trimmed != "" {
+				want = strings.Split(trimmed, "\n")
+			}
+
+			canonical, err := resolver.Canonicalize(tc.command, tc.args)
+			require.NoError(t, err)
+			require.Equal(t, want, canonical.Options)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package bazelrc
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// BazelCompatibility selects which Bazel version's handling of flags which don't apply to the invoked command a Resolver emulates.
+type BazelCompatibility int
+
+const (
+	// Bazel7Compatibility emulates Bazel 7 and later: flags in `common` sections which don't apply to the invoked command are silently ignored,
+	// and flags elsewhere (in `always` or command-specific sections, or on the command line) which don't apply are errors.
+	Bazel7Compatibility BazelCompatibility = iota
+	// Bazel6Compatibility emulates Bazel 6, where every flag must apply to the invoked command, including those in `common` sections.
+	// `always` sections are treated like `common` sections.
+	Bazel6Compatibility
+	// LenientCompatibility skips every flag which doesn't apply to the invoked command, reporting a warning for those not in `common` sections.
+	LenientCompatibility
+)
+
+// WithBazelCompatibility sets how flags which don't apply to the invoked command are handled.
+// By default, Bazel 7 is emulated.
+func WithBazelCompatibility(compatibility BazelCompatibility) ResolverOption {
+	return func(r *Resolver) {
+		r.compatibility = compatibility
+	}
+}
+
+// InapplicableFlagError is returned when a flag is used for a command it doesn't apply to, e.g. `build --test_output=errors` when running `bazel build`.
+type InapplicableFlagError struct {
+	// Location is the flag.
+	Location
+	// Flag is the name of the flag, without leading dashes.
+	Flag string
+	// Command is the invoked command.
+	Command string
+}
+
+func (e *InapplicableFlagError) Error() string {
+	return e.describeSelf(fmt.Sprintf("flag %s doesn't apply to the %s command", e.Flag, e.Command))
+}
+
+// AppliesToCommand returns whether the named flag (without leading dashes) is accepted by command, according to Commands.
+// Flags whose commands aren't known, including Starlark flags, are assumed to apply to every command.
+func (d *FlagData) AppliesToCommand(flagName string, command string) bool {
+	if d == nil || IsStarlarkFlag(flagName) {
+		return true
+	}
+	commands, known := d.Commands[flagName]
+	if !known {
+		return true
+	}
+	return slices.Contains(commands, command)
+}
+
+// checkApplicability decides whether option should be applied to the invoked command, taking into account which section it was found in (e.g. "common:ci").
+// It returns false, with any error to report, if it shouldn't be.
+func (s *resolution) checkApplicability(option Option) (bool, error) {
+	if option.Name == "config" || s.resolver.knownFlagData.AppliesToCommand(option.Name, s.command) {
+		return true, nil
+	}
+	sectionCommand, _, _ := strings.Cut(option.Command, ":")
+	err := &InapplicableFlagError{Location: newSpanLocation(nil, option.Span()), Flag: option.Name, Command: s.command}
+	switch s.resolver.compatibility {
+	case Bazel6Compatibility:
+		return false, err
+	case LenientCompatibility:
+		if sectionCommand != "common" {
+			s.diagnostics = append(s.diagnostics, newDiagnostic(SeverityWarning, err))
+		}
+		return false, nil
+	default:
+		if sectionCommand == "common" {
+			return false, nil
+		}
+		return false, err
+	}
+}
@@ -0,0 +1,130 @@
+package bazelrc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagApplicability(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{
+			"keep_going": true,
+		},
+		Commands: map[string][]string{
+			"jobs":        {"build", "test", "run"},
+			"keep_going":  {"build", "test", "run", "query"},
+			"test_output": {"test"},
+			"output":      {"query"},
+			"color":       {"build", "test", "run", "query"},
+		},
+	}
+	rcContents := `common --color=yes --output=label
+common:ci --test_output=errors --jobs=4
+build --jobs=10
+test --test_output=summary
+`
+	for name, tc := range map[string]struct {
+		rcContents    string
+		command       string
+		commandLine   []string
+		compatibility BazelCompatibility
+		want          BazelFlagValues
+		wantWarnings  []string
+		wantErr       *InapplicableFlagError
+	}{
+		"common flags which don't apply are ignored": {
+			command:     "build",
+			commandLine: []string{"--config=ci"},
+			want: BazelFlagValues{
+				"color": []string{"yes"},
+				"jobs":  []string{"10", "4"},
+			},
+		},
+		"common flags apply to other commands": {
+			command: "query",
+			want: BazelFlagValues{
+				"color":  []string{"yes"},
+				"output": []string{"label"},
+			},
+		},
+		"inherited command sections are checked against the invoked command": {
+			rcContents: "build --test_output=errors",
+			command:    "test",
+			want: BazelFlagValues{
+				"test_output": []string{"errors"},
+			},
+		},
+		"command section misuse is an error": {
+			rcContents: "build --test_output=errors",
+			command:    "build",
+			wantErr:    &InapplicableFlagError{Flag: "test_output", Command: "build"},
+		},
+		"always flags which don't apply are an error": {
+			rcContents: "always --output=label",
+			command:    "build",
+			wantErr:    &InapplicableFlagError{Flag: "output", Command: "build"},
+		},
+		"command line misuse is an error": {
+			command:     "build",
+			commandLine: []string{"--test_output=all"},
+			wantErr:     &InapplicableFlagError{Flag: "test_output", Command: "build"},
+		},
+		"bazel 6 rejects common flags which don't apply": {
+			command:       "build",
+			compatibility: Bazel6Compatibility,
+			wantErr:       &InapplicableFlagError{Flag: "output", Command: "build"},
+		},
+		"lenient skips with warnings": {
+			rcContents:    rcContents + "build --test_output=all --keep_going",
+			command:       "build",
+			commandLine:   []string{"--config=ci", "--output=json"},
+			compatibility: LenientCompatibility,
+			want: BazelFlagValues{
+				"color":      []string{"yes"},
+				"jobs":       []string{"10", "4"},
+				"keep_going": []string{"true"},
+			},
+			wantWarnings: []string{
+				"failed to process /sample/bazelrc on line 5, flag test_output doesn't apply to the build command",
+				"flag output doesn't apply to the build command",
+			},
+		},
+		"unknown and starlark flags apply everywhere": {
+			rcContents: "common --unknown_flag=1 --//pkg:flag=2",
+			command:    "query",
+			want: BazelFlagValues{
+				"unknown_flag": []string{"1"},
+				"//pkg:flag":   []string{"2"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if tc.rcContents == "" {
+				tc.rcContents = rcContents
+			}
+			contents, err := NewBazelRcParser("", flagData).Parsefile(strings.NewReader(tc.rcContents), "/sample/bazelrc")
+			require.NoError(t, err)
+			commandLine, err := ParseCommandLineArgsAfterCommand(flagData, tc.commandLine)
+			require.NoError(t, err)
+			resolved, err := NewResolver(contents, flagData, WithBazelCompatibility(tc.compatibility)).Resolve(tc.command, commandLine.Options)
+			if tc.wantErr != nil {
+				var inapplicableErr *InapplicableFlagError
+				require.True(t, errors.As(err, &inapplicableErr), "got error %v", err)
+				require.Equal(t, tc.wantErr.Flag, inapplicableErr.Flag)
+				require.Equal(t, tc.wantErr.Command, inapplicableErr.Command)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, resolved.Values())
+			var warnings []string
+			for _, diagnostic := range resolved.Diagnostics {
+				require.Equal(t, SeverityWarning, diagnostic.Severity)
+				warnings = append(warnings, diagnostic.Err.Error())
+			}
+			require.Equal(t, tc.wantWarnings, warnings)
+		})
+	}
+}
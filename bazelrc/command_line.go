@@ -12,6 +12,10 @@ type CommandLineArgsAfterCommand struct {
 	BazelFlags BazelFlagValues
 	// ExecutableArgs contains anything that came after a standalone `--` flag.
 	ExecutableArgs []string
+	// Options contains every Bazel flag in the order it was found, with spans identifying the arguments it came from.
+	Options []Option
+	// FlagAliases contains the aliases declared by `--flag_alias`, mapping alias names to normalized labels.
+	FlagAliases map[string]string
 }
 
 // ParseCommandLineArgsAfterCommand parses a command line (rather than a bazelrc line) to find a list of targets and arguments there-to.
@@ -22,19 +26,35 @@ func ParseCommandLineArgsAfterCommand(knownFlagData *FlagData, tokens []string)
 	// Fortunately, our command line also can't include `import` directives,
 	// which is the only thing the workspace directory is used for, so this doesn't really matter.
 	parser := NewBazelRcParser("", knownFlagData)
-	argAccumulator := make(map[string][]string)
+	argTokens := make([]Token, 0, len(tokens))
+	for i, token := range tokens {
+		argTokens = append(argTokens, newArgToken(token, i))
+	}
+	var options []Option
 	var targetsAndArgsAccumulator []string
-	var flagNameExpectingValueWithLeadingDashes *string
-	continuation, err := parser.parseTokenizedArgsOnSingleLineAfterCommand(tokens, argAccumulator, &targetsAndArgsAccumulator, &flagNameExpectingValueWithLeadingDashes, nil, -1)
+	var flagExpectingValue *pendingFlag
+	flagAliases := copyFlagAliases(knownFlagData)
+	continuation, err := parser.parseTokenizedArgsOnSingleLineAfterCommand(argTokens, &options, &targetsAndArgsAccumulator, &flagExpectingValue, flagAliases, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse bazel command line: %w", err)
 	}
 	if continuation {
 		return nil, fmt.Errorf("didn't understand continuation \\ at end of command")
 	}
-	if flagNameExpectingValueWithLeadingDashes != nil {
-		return nil, fmt.Errorf("internal error: ended parsing Bazel command line while expecting value for flag %q", *flagNameExpectingValueWithLeadingDashes)
+	if flagExpectingValue != nil {
+		return nil, fmt.Errorf("failed to parse bazel command line: %w", &MissingFlagValueError{Location: newSpanLocation(nil, flagExpectingValue.token.Span), Flag: stripLeadingDashes(flagExpectingValue.nameWithLeadingDashes)})
+	}
+	if err := parser.normalizeOptionValues(options, nil); err != nil {
+		return nil, fmt.Errorf("failed to parse bazel command line: %w", err)
+	}
+
+	argAccumulator := make(BazelFlagValues)
+	declaredFlagAliases := make(map[string]string)
+	for _, option := range options {
+		argAccumulator[option.Name] = append(argAccumulator[option.Name], option.Value)
 	}
+	// Aliases were validated as they were declared, so there's no error to handle here.
+	_ = recordFlagAliases(options, declaredFlagAliases, nil)
 
 	targets := targetsAndArgsAccumulator
 	var args []string
@@ -50,6 +70,8 @@ func ParseCommandLineArgsAfterCommand(knownFlagData *FlagData, tokens []string)
 		Targets:        targets,
 		BazelFlags:     argAccumulator,
 		ExecutableArgs: args,
+		Options:        options,
+		FlagAliases:    declaredFlagAliases,
 	}
 
 	return ret, nil
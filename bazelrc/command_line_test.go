@@ -64,7 +64,42 @@ func TestParseCommandLineArgs(t *testing.T) {
 			}
 			got, err := ParseCommandLineArgsAfterCommand(flagData, tc.in)
 			require.NoError(t, err)
-			require.Equal(t, tc.want, got)
+			require.Equal(t, tc.want.Targets, got.Targets)
+			require.Equal(t, tc.want.BazelFlags, got.BazelFlags)
+			require.Equal(t, tc.want.ExecutableArgs, got.ExecutableArgs)
 		})
 	}
 }
+
+func TestParseCommandLineArgsPositions(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{
+			"verbose_failures": true,
+		},
+		FlagAbbreviations: map[string]string{
+			"j": "jobs",
+		},
+	}
+	got, err := ParseCommandLineArgsAfterCommand(flagData, []string{"//some:target", "--copt=-g", "-j", "8", "--noverbose_failures"})
+	require.NoError(t, err)
+
+	arg := func(index, offset int) Position {
+		return Position{Column: offset + 1, Offset: offset, ArgIndex: index}
+	}
+	require.Len(t, got.Options, 3)
+
+	require.Equal(t, "copt", got.Options[0].Name)
+	require.Equal(t, Span{arg(1, 0), arg(1, 6)}, got.Options[0].NameSpan)
+	require.Equal(t, Span{arg(1, 7), arg(1, 9)}, got.Options[0].ValueSpan)
+	require.True(t, got.Options[0].NameSpan.Start.IsCommandLine())
+
+	require.Equal(t, "jobs", got.Options[1].Name)
+	require.Equal(t, "8", got.Options[1].Value)
+	require.Equal(t, Span{arg(2, 0), arg(2, 2)}, got.Options[1].NameSpan)
+	require.Equal(t, Span{arg(3, 0), arg(3, 1)}, got.Options[1].ValueSpan)
+	require.Equal(t, Span{arg(2, 0), arg(3, 1)}, got.Options[1].Span())
+
+	require.Equal(t, "verbose_failures", got.Options[2].Name)
+	require.Equal(t, "false", got.Options[2].Value)
+	require.True(t, got.Options[2].ValueSpan.IsZero())
+}
@@ -0,0 +1,231 @@
+package bazelrc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// platformConfigs lists the configs `--enable_platform_specific_config` may apply, one for each OS Bazel supports.
+var platformConfigs = []string{"linux", "macos", "windows", "freebsd", "openbsd"}
+
+// ParseInvocation parses the invocation of a Bazel command line argv (including the Bazel binary, e.g. `["bazel", "test", "--config=ci", "//..."]`),
+// e.g. from a CI script or a `tools/bazel` wrapper.
+func ParseInvocation(knownFlagData *FlagData, argv []string) (Invocation, error) {
+	if len(argv) == 0 {
+		return Invocation{}, errors.New("failed to parse invocation: no arguments")
+	}
+	startupArgs, err := ParseCommandLineStartupArgs(knownFlagData, argv[1:])
+	if err != nil {
+		return Invocation{}, err
+	}
+	if startupArgs.Command == "" {
+		return Invocation{}, errors.New("failed to parse invocation: no command")
+	}
+	commandArgs, err := ParseCommandLineArgsAfterCommand(knownFlagData, startupArgs.ArgsAfterCommand)
+	if err != nil {
+		return Invocation{}, err
+	}
+	invocation := Invocation{Command: startupArgs.Command}
+	for _, option := range commandArgs.Options {
+		if option.Name == "config" {
+			invocation.Configs = append(invocation.Configs, option.Value)
+		}
+	}
+	return invocation, nil
+}
+
+// ConfigDefinition is a line of a bazelrc file which sets options for a config, e.g. `build:ci --keep_going`.
+type ConfigDefinition struct {
+	// Config is the name of the config, e.g. "ci".
+	Config string
+	// Command is the command the config is defined for, e.g. "build".
+	Command string
+	// Span covers the command and config name at the start of the line.
+	Span Span
+	// Options are the options on the line.
+	Options []Option
+}
+
+func (d ConfigDefinition) String() string {
+	return fmt.Sprintf("%s: %s:%s", d.Span.Start, d.Command, d.Config)
+}
+
+// ConfigUsage describes the config definitions of a bazelrc file which can be removed without changing any invocation.
+// Each list is in the order the definitions appear in the bazelrc file.
+type ConfigUsage struct {
+	// Unreachable lists the definitions of configs which nothing can apply:
+	// they aren't used by any of the known invocations, by a section which applies to them, by `--enable_platform_specific_config`, or by another such config.
+	Unreachable []ConfigDefinition
+	// UnrunCommands lists the definitions of reachable configs for commands which none of the known invocations run (e.g. `coverage:ci` if only `build` and `test` are run), which are never applied.
+	// It is empty if there are no known invocations.
+	UnrunCommands []ConfigDefinition
+	// Shadowed lists definitions whose every option is overridden by a later definition of the same config for the same command,
+	// e.g. `build:ci --jobs=10` followed by `build:ci --jobs=20`.
+	// Options of flags which accumulate values, or which aren't known not to (i.e. which aren't in FlagData.AllowsMultiple), `--config` options and expansion flags are never overridden.
+	Shadowed []ConfigDefinition
+}
+
+// AnalyzeConfigUsage finds the config definitions in contents which are unreachable, for commands no one runs, or shadowed by later definitions.
+// Configs are reachable from `--config` options in sections which aren't for configs (including through expansion flags), from `--enable_platform_specific_config` (which may apply the config of any OS),
+// from invocations, which are the known ways Bazel is invoked (e.g. from CI scripts), and from `--config` options in other reachable configs.
+// If invocations is empty, configs are assumed to be used only from the bazelrc file, and every command is assumed to be run;
+// otherwise only sections for commands in the CommandChain of some invocation are considered.
+func AnalyzeConfigUsage(contents *BazelrcContents, knownFlagData *FlagData, invocations []Invocation) *ConfigUsage {
+	var definitions []ConfigDefinition
+	definitionsByConfig := make(map[string][]int)
+	// Options of sections which aren't for configs, which are applied whenever their command is run.
+	var unconfigured []Option
+	for _, option := range contents.Options() {
+		command, config, hasConfig := strings.Cut(option.Command, ":")
+		if !hasConfig {
+			unconfigured = append(unconfigured, option)
+			continue
+		}
+		// Options on the same line share their CommandSpan.
+		if last := len(definitions) - 1; last >= 0 && definitions[last].Span == option.CommandSpan && definitions[last].Command == command && definitions[last].Config == config {
+			definitions[last].Options = append(definitions[last].Options, option)
+			continue
+		}
+		definitionsByConfig[config] = append(definitionsByConfig[config], len(definitions))
+		definitions = append(definitions, ConfigDefinition{Config: config, Command: command, Span: option.CommandSpan, Options: []Option{option}})
+	}
+
+	var runCommands map[string]bool
+	if len(invocations) != 0 {
+		runCommands = make(map[string]bool)
+		for _, invocation := range invocations {
+			for _, command := range CommandChain(invocation.Command) {
+				runCommands[command] = true
+			}
+		}
+	}
+	isRun := func(command string) bool {
+		return runCommands == nil || runCommands[command]
+	}
+
+	reachable := make(map[string]bool)
+	var pending []string
+	reach := func(config string) {
+		if !reachable[config] {
+			reachable[config] = true
+			pending = append(pending, config)
+		}
+	}
+	reachFrom := func(option Option) {
+		for _, config := range referencedConfigs(knownFlagData, option) {
+			reach(config)
+		}
+	}
+	for _, invocation := range invocations {
+		for _, config := range invocation.Configs {
+			reach(config)
+		}
+	}
+	for _, option := range unconfigured {
+		if option.Command != "startup" && isRun(option.Command) {
+			reachFrom(option)
+		}
+	}
+	for len(pending) != 0 {
+		config := pending[0]
+		pending = pending[1:]
+		for _, i := range definitionsByConfig[config] {
+			if !isRun(definitions[i].Command) {
+				continue
+			}
+			for _, option := range definitions[i].Options {
+				reachFrom(option)
+			}
+		}
+	}
+
+	usage := &ConfigUsage{}
+	for _, definition := range definitions {
+		switch {
+		case !reachable[definition.Config]:
+			usage.Unreachable = append(usage.Unreachable, definition)
+		case !isRun(definition.Command):
+			usage.UnrunCommands = append(usage.UnrunCommands, definition)
+		}
+	}
+	for i, definition := range definitions {
+		if isShadowed(knownFlagData, definition, definitions[i+1:]) {
+			usage.Shadowed = append(usage.Shadowed, definition)
+		}
+	}
+	return usage
+}
+
+// referencedConfigs returns the configs option applies: its value if it's a `--config` option, those in its expansion if it's an expansion flag,
+// or every platform config if it enables `--enable_platform_specific_config`.
+func referencedConfigs(knownFlagData *FlagData, option Option) []string {
+	if option.Name == "config" {
+		return []string{option.Value}
+	}
+	if option.Name == "enable_platform_specific_config" {
+		if option.Value == "true" {
+			return platformConfigs
+		}
+		return nil
+	}
+	expansion, ok := knownFlagData.expansion(option.Name)
+	if !ok {
+		return nil
+	}
+	var configs []string
+	for _, arg := range expansion {
+		if config, ok := strings.CutPrefix(arg, "--config="); ok {
+			configs = append(configs, config)
+		}
+	}
+	return configs
+}
+
+// isShadowed returns whether every option of definition is overridden by an option of a later definition of the same config for the same command.
+// For flags like `--define`, where a later value for a name overrides earlier ones, only a later value for the same name overrides an option.
+func isShadowed(knownFlagData *FlagData, definition ConfigDefinition, later []ConfigDefinition) bool {
+	overrides := func(option Option, laterOption Option) bool {
+		if laterOption.Name != option.Name {
+			return false
+		}
+		if keyedOverrideFlags[option.Name] {
+			name, _, _ := strings.Cut(option.Value, "=")
+			laterName, _, _ := strings.Cut(laterOption.Value, "=")
+			return name == laterName
+		}
+		return true
+	}
+	for _, option := range definition.Options {
+		if _, isExpansion := knownFlagData.expansion(option.Name); option.Name == "config" || isExpansion {
+			return false
+		}
+		if !keyedOverrideFlags[option.Name] && !knownFlagData.isSingleValued(option.Name) {
+			return false
+		}
+		overridden := false
+		for _, laterDefinition := range later {
+			if laterDefinition.Config != definition.Config || laterDefinition.Command != definition.Command {
+				continue
+			}
+			for _, laterOption := range laterDefinition.Options {
+				if overrides(option, laterOption) {
+					overridden = true
+				}
+			}
+		}
+		if !overridden {
+			return false
+		}
+	}
+	return true
+}
+
+// isSingleValued returns whether flag is known not to accumulate values, so that a later value overrides an earlier one.
+func (d *FlagData) isSingleValued(flag string) bool {
+	if d == nil {
+		return false
+	}
+	allowsMultiple, known := d.AllowsMultiple[flag]
+	return known && !allowsMultiple
+}
@@ -0,0 +1,157 @@
+package bazelrc
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeConfigUsage(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags:   map[string]bool{"keep_going": true, "enable_platform_specific_config": true},
+		AllowsMultiple: map[string]bool{"copt": true, "jobs": false, "keep_going": false},
+		Expansions:     map[string][]string{"fast_build": {"--jobs=100", "--config=remote"}},
+	}
+	contents, err := NewBazelRcParser(".", flagData, WithFS(fstest.MapFS{
+		".bazelrc": &fstest.MapFile{Data: []byte(`common --enable_platform_specific_config
+build --config=base
+test --fast_build
+build:base --config=cache
+build:cache --disk_cache=/tmp/cache
+build:remote --remote_executor=grpc://example
+build:linux --copt=-DLINUX
+build:ci --keep_going --jobs=10
+build:ci --copt=-O2
+build:ci --jobs=20 --nokeep_going
+build:ci --define=a=1 --define=b=2
+build:ci --define=b=3 --define=a=3
+coverage:ci --combined_report=lcov
+coverage:cov --combined_report=lcov
+build:old --jobs=1
+build:old --config=older
+build:older --jobs=2
+`)},
+	})).ParsePath(".bazelrc")
+	require.NoError(t, err)
+
+	configs := func(definitions []ConfigDefinition) []string {
+		var configs []string
+		for _, definition := range definitions {
+			configs = append(configs, definition.String())
+		}
+		return configs
+	}
+
+	for name, tc := range map[string]struct {
+		invocations       []Invocation
+		wantUnreachable   []string
+		wantUnrunCommands []string
+		wantShadowed      []string
+	}{
+		"no invocations": {
+			wantUnreachable: []string{
+				".bazelrc:8:1: build:ci",
+				".bazelrc:9:1: build:ci",
+				".bazelrc:10:1: build:ci",
+				".bazelrc:11:1: build:ci",
+				".bazelrc:12:1: build:ci",
+				".bazelrc:13:1: coverage:ci",
+				".bazelrc:14:1: coverage:cov",
+				".bazelrc:15:1: build:old",
+				".bazelrc:16:1: build:old",
+				".bazelrc:17:1: build:older",
+			},
+			wantShadowed: []string{
+				".bazelrc:8:1: build:ci",
+				".bazelrc:11:1: build:ci",
+			},
+		},
+		"invocations": {
+			invocations: []Invocation{
+				{Command: "build", Configs: []string{"ci"}},
+				{Command: "run"},
+				{Command: "build", Configs: []string{"cov"}},
+			},
+			wantUnreachable: []string{
+				".bazelrc:6:1: build:remote",
+				".bazelrc:15:1: build:old",
+				".bazelrc:16:1: build:old",
+				".bazelrc:17:1: build:older",
+			},
+			wantUnrunCommands: []string{
+				".bazelrc:13:1: coverage:ci",
+				".bazelrc:14:1: coverage:cov",
+			},
+			wantShadowed: []string{
+				".bazelrc:8:1: build:ci",
+				".bazelrc:11:1: build:ci",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			usage := AnalyzeConfigUsage(contents, flagData, tc.invocations)
+			require.Equal(t, tc.wantUnreachable, configs(usage.Unreachable))
+			require.Equal(t, tc.wantUnrunCommands, configs(usage.UnrunCommands))
+			require.Equal(t, tc.wantShadowed, configs(usage.Shadowed))
+		})
+	}
+}
+
+func TestAnalyzeConfigUsageWithoutAllowsMultiple(t *testing.T) {
+	for name, flagData := range map[string]*FlagData{
+		"no flag data":      nil,
+		"no AllowsMultiple": {BooleanFlags: map[string]bool{"keep_going": true}},
+		"unlisted flags":    {AllowsMultiple: map[string]bool{"keep_going": false}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			contents, err := NewBazelRcParser(".", flagData, WithFS(fstest.MapFS{
+				".bazelrc": &fstest.MapFile{Data: []byte(`build:ci --copt=-O2
+build:ci --copt=-g
+build:ci --define=a=1
+build:ci --define=a=2
+`)},
+			})).ParsePath(".bazelrc")
+			require.NoError(t, err)
+
+			usage := AnalyzeConfigUsage(contents, flagData, []Invocation{{Command: "build", Configs: []string{"ci"}}})
+			require.Len(t, usage.Shadowed, 1)
+			require.Equal(t, ".bazelrc:3:1: build:ci", usage.Shadowed[0].String())
+		})
+	}
+}
+
+func TestParseInvocation(t *testing.T) {
+	for name, tc := range map[string]struct {
+		argv    []string
+		want    Invocation
+		wantErr string
+	}{
+		"configs": {
+			argv: []string{"bazel", "--output_base=/tmp/out", "test", "--config=ci", "--keep_going", "//...", "--config", "remote"},
+			want: Invocation{Command: "test", Configs: []string{"ci", "remote"}},
+		},
+		"no configs": {
+			argv: []string{"bazel", "build", "//foo"},
+			want: Invocation{Command: "build"},
+		},
+		"no command": {
+			argv:    []string{"bazel", "--output_base=/tmp/out"},
+			wantErr: "failed to parse invocation: no command",
+		},
+		"no arguments": {
+			wantErr: "failed to parse invocation: no arguments",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			flagData := &FlagData{BooleanFlags: map[string]bool{"keep_going": true}}
+			got, err := ParseInvocation(flagData, tc.argv)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -1,14 +1,65 @@
 package bazelrc
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
 // BazelrcContents holds the output of parsing a Bazelrc file.
 type BazelrcContents struct {
 	// entries is a map of commands (e.g. "build") to flag names without leading dashes (e.g. "action_env")
 	// to values (in the order they were encountered in the file).
 	entries map[string]BazelFlagValues
+	// options holds every option in the order it was encountered, including those from imported files.
+	options []Option
+	// consultedFiles lists every file which was read or probed, in the order it was first encountered.
+	consultedFiles []ConsultedFile
+	// flagAliases maps the names of aliases declared by `--flag_alias` to the labels they stand for.
+	flagAliases map[string]string
+	// lines holds the arguments of every line of options, in the order they were encountered, as Bazel's client passes them to its server.
+	lines []rcLine
+	// diagnostics holds any warnings found while parsing, and any errors when parsing with ParsefileCollectingDiagnostics.
+	diagnostics []Diagnostic
 }
 
 type BazelFlagValues map[string][]string
 
+// rcLine is a logical line of a bazelrc file which specifies options for a command.
+type rcLine struct {
+	// command is the command the line is for, including any config name (e.g. "build:ci").
+	command string
+	// source is the path of the file containing the line.
+	source string
+	// args are the tokens following the command, including any targets.
+	args []Token
+}
+
+// Option is a single occurrence of a flag, in a bazelrc file or on a command line.
+type Option struct {
+	// Command is the command the option was specified for, including any config name (e.g. "build" or "build:ci").
+	// It is empty for options from a command line.
+	Command string
+	// CommandSpan covers the command (and config name), and is the zero Span for options from a command line.
+	CommandSpan Span
+	// Name is the name of the flag without leading dashes, after expanding abbreviations and removing any `no` prefix for boolean flags.
+	// Starlark flags are named by their label, normalized by NormalizeLabel (e.g. "//pkg:flag").
+	Name string
+	// Value is the value of the flag, which is "true" or "false" for boolean flags without an explicit value.
+	Value string
+	// Tokens are the tokens the option was parsed from; usually one (e.g. `--jobs=10`), but two if the value was a separate token (e.g. `--jobs 10`).
+	Tokens []Token
+	// NameSpan covers the flag's name, including leading dashes (and any `no` prefix).
+	NameSpan Span
+	// ValueSpan covers the flag's value, and is the zero Span if the value was implied (e.g. `--nobool_flag`).
+	ValueSpan Span
+}
+
+// Span covers the whole option, excluding its command.
+func (o Option) Span() Span {
+	return joinSpans(o.Tokens[0].Span, o.Tokens[len(o.Tokens)-1].Span)
+}
+
 // FlagValue gets the effective (i.e. last) value for a particular flag.
 // It has no awareness of what flags are allowed multiple values, or default values.
 func (c BazelFlagValues) FlagValue(flagname string) *string {
@@ -30,8 +81,79 @@ func (c *BazelrcContents) FlagValue(command string, flagname string) *string {
 	return commandSpecificFlags.FlagValue(flagname)
 }
 
+// Options returns every option which was parsed, in the order it was encountered (with options from imported files appearing where they were imported).
+func (c *BazelrcContents) Options() []Option {
+	return c.options
+}
+
+func (c *BazelrcContents) addOption(option Option) {
+	if _, ok := c.entries[option.Command]; !ok {
+		c.entries[option.Command] = make(BazelFlagValues)
+	}
+	c.entries[option.Command][option.Name] = append(c.entries[option.Command][option.Name], option.Value)
+	c.options = append(c.options, option)
+	if option.Name == "flag_alias" && declaresFlagAliases(option.Command) {
+		// The value was validated when the alias was recorded during parsing.
+		if name, label, err := splitFlagAlias(option.Value); err == nil {
+			c.flagAliases[name] = label
+		}
+	}
+}
+
+// ConsultedFile describes a file which was read (or which was looked for but didn't exist) while parsing.
+type ConsultedFile struct {
+	// Path is the path of the file, as resolved by the parser's ImportResolver.
+	Path string
+	// Exists is false for files which couldn't be opened, e.g. `try-import`s of files which don't exist.
+	Exists bool
+	// SHA256 is the hex-encoded SHA-256 digest of the file's contents, or empty if the file doesn't exist.
+	SHA256 string
+}
+
+// ConsultedFiles returns every file which was read while parsing (including the top-level file), and every file which was looked for but couldn't be opened.
+// Each path is listed once, in the order it was first encountered.
+// If any of these files appears, disappears, or changes, parsing again may give a different result.
+func (c *BazelrcContents) ConsultedFiles() []ConsultedFile {
+	return c.consultedFiles
+}
+
+// ConsultedFilesDigest returns a hex-encoded SHA-256 digest of ConsultedFiles, suitable for use as a cache key for the result of parsing.
+func (c *BazelrcContents) ConsultedFilesDigest() string {
+	hash := sha256.New()
+	for _, file := range c.consultedFiles {
+		// Paths can't contain NUL bytes, so this is unambiguous.
+		fmt.Fprintf(hash, "%s\x00%t\x00%s\x00", file.Path, file.Exists, file.SHA256)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// recordConsultedFile records that the file at path was consulted, and what its contents were if it exists.
+func (c *BazelrcContents) recordConsultedFile(path string, exists bool, contents []byte) {
+	consultedFile := ConsultedFile{
+		Path:   path,
+		Exists: exists,
+	}
+	if exists {
+		digest := sha256.Sum256(contents)
+		consultedFile.SHA256 = hex.EncodeToString(digest[:])
+	}
+	for _, existing := range c.consultedFiles {
+		if existing == consultedFile {
+			return
+		}
+	}
+	c.consultedFiles = append(c.consultedFiles, consultedFile)
+}
+
+// Diagnostics returns the problems found while parsing.
+// Warnings (e.g. about duplicate imports) are always recorded, but errors are only recorded when parsing with ParsefileCollectingDiagnostics.
+func (c *BazelrcContents) Diagnostics() []Diagnostic {
+	return c.diagnostics
+}
+
 func newBazelrcContents() *BazelrcContents {
 	return &BazelrcContents{
-		entries: make(map[string]BazelFlagValues),
+		entries:     make(map[string]BazelFlagValues),
+		flagAliases: make(map[string]string),
 	}
 }
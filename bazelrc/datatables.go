@@ -5,7 +5,9 @@ import (
 	"encoding/base64"
 	"fmt"
 	"os/exec"
+	"strings"
 
+	"golang.org/x/exp/slices"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/bazel-contrib/bazelrc-parser-go/bazel_protos/bazel_flags"
@@ -15,9 +17,43 @@ import (
 // Note that this schema changes across different Bazel versions.
 type FlagData struct {
 	// BooleanFlags contains the flags which are enabled without specifying a value for the flag (e.g. --subcommands).
+	// Such flags aren't necessarily booleans (e.g. `--subcommands=pretty_print`), so their values are only validated and normalized while parsing if their type is given by ValueTypes.
 	BooleanFlags map[string]bool
 	// FlagAbbreviation maps short names to long names, e.g. maps `j` to `jobs`.
 	FlagAbbreviations map[string]string
+	// Commands optionally lists the commands which accept each flag (e.g. `test_output` is accepted by `test` and `coverage`, but not `build`).
+	// Flags which aren't listed are assumed to be accepted by every command.
+	Commands map[string][]string
+	// ValueTypes optionally gives the type of each flag's value, which is used to validate values and normalize their spelling.
+	// Flags without a type are treated as strings, even if they're in BooleanFlags, so their values are kept as written.
+	// Flags whose type is ValueTypeBoolean or ValueTypeTriState may be given without a value, like those in BooleanFlags.
+	ValueTypes map[string]ValueType
+	// EnumValues lists the allowed values of each flag whose type is ValueTypeEnum, in their canonical spelling.
+	EnumValues map[string][]string
+	// DefaultValues optionally gives the default value of each flag which has one.
+	DefaultValues map[string]string
+	// AllowsMultiple optionally records which flags accumulate values when used multiple times (e.g. `copt`), rather than the last value winning.
+	AllowsMultiple map[string]bool
+	// EffectTags optionally gives the effect tags of each flag, in lower case (e.g. `affects_outputs`), which describe what setting the flag affects.
+	// They are used by Impact to classify the effect of changing a flag.
+	EffectTags map[string][]string
+	// MetadataTags optionally gives the metadata tags of each flag (e.g. `experimental`).
+	MetadataTags map[string][]string
+	// Expansions optionally gives the flags each expansion flag expands to (e.g. `--experimental_spawn_scheduler` expands to `--internal_spawn_scheduler --spawn_strategy=dynamic`).
+	// Expansion flags which aren't listed here but are in BundledExpansions are still expanded.
+	Expansions map[string][]string
+	// BuildSettings optionally gives the types of Starlark build settings (e.g. `//pkg:flag`), keyed by label.
+	// It isn't populated by GetFlagDataFromBazel, as build settings are defined by the workspace rather than by Bazel.
+	// Starlark flags whose type isn't known are assumed to be boolean when given without a value.
+	BuildSettings map[string]BuildSettingType
+	// FlagAliases optionally maps alias names to the labels of the Starlark flags they stand for, as if declared by `--flag_alias=name=label`.
+	// This allows e.g. aliases declared on the command line to apply while parsing bazelrc files, or vice versa.
+	// Aliases declared while parsing are applied in addition to these, to the flags which follow them.
+	FlagAliases map[string]string
+	// StartupOptions optionally gives the schema of startup options (those which appear before the command, e.g. `--output_base`), which is used for `startup` lines and by ParseCommandLineStartupArgs.
+	// It is populated from the section of help output listing startup options (e.g. `bazel help startup_options`), and by GetFlagDataFromBazel.
+	// If it is nil, startup options are parsed using this schema.
+	StartupOptions *FlagData
 }
 
 // GetFlagDataFromBazel returns a FlagData by invoking bazel to learn about flags.
@@ -44,9 +80,32 @@ func GetFlagDataFromBazel(command *exec.Cmd) (*FlagData, error) {
 
 	booleanFlags := make(map[string]bool)
 	flagAbbreviations := make(map[string]string)
+	commands := make(map[string][]string)
+	allowsMultiple := make(map[string]bool)
+	effectTags := make(map[string][]string)
+	metadataTags := make(map[string][]string)
+	var startupOptions *FlagData
 
 	for _, flag := range flags.FlagInfos {
+		if slices.Contains(flag.GetCommands(), "startup") {
+			if startupOptions == nil {
+				startupOptions = &FlagData{BooleanFlags: make(map[string]bool)}
+			}
+			startupOptions.BooleanFlags[flag.GetName()] = !flag.GetRequiresValue()
+			if len(flag.GetCommands()) == 1 {
+				continue
+			}
+		}
 		booleanFlags[flag.GetName()] = !flag.GetRequiresValue()
+		commands[flag.GetName()] = flag.GetCommands()
+		allowsMultiple[flag.GetName()] = flag.GetAllowsMultiple()
+		// The proto spells tags like the enum values (e.g. `AFFECTS_OUTPUTS`), whereas help output (and so EffectTags) spells them in lower case.
+		for _, tag := range flag.GetEffectTags() {
+			effectTags[flag.GetName()] = append(effectTags[flag.GetName()], strings.ToLower(tag))
+		}
+		for _, tag := range flag.GetMetadataTags() {
+			metadataTags[flag.GetName()] = append(metadataTags[flag.GetName()], strings.ToLower(tag))
+		}
 		if flag.Abbreviation != nil {
 			if len(flag.GetAbbreviation()) != 1 {
 				return nil, fmt.Errorf("saw flag %q abbreviates to %q but expect all flag abbreviations to be single characters", flag.GetName(), flag.GetAbbreviation())
@@ -58,5 +117,10 @@ func GetFlagDataFromBazel(command *exec.Cmd) (*FlagData, error) {
 	return &FlagData{
 		BooleanFlags:      booleanFlags,
 		FlagAbbreviations: flagAbbreviations,
+		Commands:          commands,
+		AllowsMultiple:    allowsMultiple,
+		EffectTags:        effectTags,
+		MetadataTags:      metadataTags,
+		StartupOptions:    startupOptions,
 	}, nil
 }
@@ -0,0 +1,166 @@
+package bazelrc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	rcSourcePrefix        = "--rc_source="
+	defaultOverridePrefix = "--default_override="
+	// clientRcSource is the rc source Bazel's client attributes options it adds itself to, e.g. `--isatty`.
+	clientRcSource = "client"
+)
+
+// DefaultOverride is a single argument of a bazelrc line, as passed from Bazel's client to its server.
+type DefaultOverride struct {
+	// RcSource is the path of the file the argument came from, or "client" for arguments added by the client itself.
+	RcSource string
+	// Command is the command the argument is for, including any config name (e.g. "build" or "build:ci").
+	Command string
+	// Arg is the argument, e.g. `--jobs=10`. Flags whose value was a separate word in the bazelrc file are passed as two arguments.
+	Arg string
+}
+
+// EncodeDefaultOverrides returns the `--rc_source` and `--default_override` arguments with which Bazel's client passes the contents of bazelrc files to its server.
+// rcContents should be given in the order Bazel reads them, i.e. the system, workspace, home and `--bazelrc` files.
+// clientArgs are the arguments the client adds to the `common` command itself, e.g. `--isatty=1` and `--terminal_columns=80`.
+// `startup` lines are omitted, as they're handled by the client.
+func EncodeDefaultOverrides(clientArgs []string, rcContents ...*BazelrcContents) []string {
+	encoded := []string{rcSourcePrefix + clientRcSource}
+	for _, arg := range clientArgs {
+		encoded = append(encoded, fmt.Sprintf("%s0:common=%s", defaultOverridePrefix, arg))
+	}
+
+	// Indexes start at 1, as 0 is the client.
+	rcSourceIndexes := make(map[string]int)
+	rcSourceCount := 1
+	for _, contents := range rcContents {
+		for _, file := range contents.consultedFiles {
+			// The same file may be read more than once (e.g. if it's imported by several rc files), but is only passed once.
+			if _, ok := rcSourceIndexes[file.Path]; ok || !file.Exists {
+				continue
+			}
+			rcSourceIndexes[file.Path] = rcSourceCount
+			rcSourceCount++
+			encoded = append(encoded, rcSourcePrefix+file.Path)
+		}
+	}
+
+	// Bazel's client passes the arguments of each rc file in turn (including those of the files it imports), and within each rc file, the arguments for each command in turn, sorted by command.
+	for _, contents := range rcContents {
+		argsByCommand := make(map[string][]DefaultOverride)
+		for _, line := range contents.lines {
+			for _, arg := range line.args {
+				argsByCommand[line.command] = append(argsByCommand[line.command], DefaultOverride{RcSource: line.source, Command: line.command, Arg: arg.Value})
+			}
+		}
+		commands := make([]string, 0, len(argsByCommand))
+		for command := range argsByCommand {
+			if command != "startup" {
+				commands = append(commands, command)
+			}
+		}
+		sort.Strings(commands)
+		for _, command := range commands {
+			for _, override := range argsByCommand[command] {
+				encoded = append(encoded, fmt.Sprintf("%s%d:%s=%s", defaultOverridePrefix, rcSourceIndexes[override.RcSource], override.Command, override.Arg))
+			}
+		}
+	}
+	return encoded
+}
+
+// DecodeDefaultOverrides finds the `--rc_source` and `--default_override` arguments in args (e.g. the arguments of a Bazel server process), and decodes each `--default_override`.
+// Other arguments are ignored.
+func DecodeDefaultOverrides(args []string) ([]DefaultOverride, error) {
+	overrides, _, err := decodeDefaultOverrides(args)
+	return overrides, err
+}
+
+// decodeDefaultOverrides decodes the `--default_override` arguments in args, also returning the index in args of each one.
+func decodeDefaultOverrides(args []string) ([]DefaultOverride, []int, error) {
+	var rcSources []string
+	var overrides []DefaultOverride
+	var argIndexes []int
+	for i, arg := range args {
+		if rcSource, ok := strings.CutPrefix(arg, rcSourcePrefix); ok {
+			rcSources = append(rcSources, rcSource)
+			continue
+		}
+		encoded, ok := strings.CutPrefix(arg, defaultOverridePrefix)
+		if !ok {
+			continue
+		}
+		index, rest, foundColon := strings.Cut(encoded, ":")
+		command, overrideArg, foundEquals := strings.Cut(rest, "=")
+		if !foundColon || !foundEquals {
+			return nil, nil, fmt.Errorf("failed to decode %q: expected --default_override=<index>:<command>=<arg>", arg)
+		}
+		rcSourceIndex, err := strconv.Atoi(index)
+		if err != nil || rcSourceIndex < 0 || rcSourceIndex >= len(rcSources) {
+			return nil, nil, fmt.Errorf("failed to decode %q: %q isn't the index of an earlier --rc_source", arg, index)
+		}
+		overrides = append(overrides, DefaultOverride{RcSource: rcSources[rcSourceIndex], Command: command, Arg: overrideArg})
+		argIndexes = append(argIndexes, i)
+	}
+	return overrides, argIndexes, nil
+}
+
+// ParseDefaultOverrides parses the options encoded in the `--default_override` arguments in args (e.g. as captured from a Bazel server process), as if they had been read from bazelrc files.
+// Consecutive arguments from the same file for the same command are parsed together, so flags whose value is a separate argument are understood.
+// Each option's position identifies the `--default_override` argument it came from.
+// Arguments added by the client itself (from rc source "client") are included, for the `common` command.
+func ParseDefaultOverrides(knownFlagData *FlagData, args []string) (*BazelrcContents, error) {
+	overrides, argIndexes, err := decodeDefaultOverrides(args)
+	if err != nil {
+		return nil, err
+	}
+	contents := newBazelrcContents()
+	for start := 0; start < len(overrides); {
+		end := start + 1
+		for end < len(overrides) && overrides[end].RcSource == overrides[start].RcSource && overrides[end].Command == overrides[start].Command {
+			end++
+		}
+		command := overrides[start].Command
+		parser := NewBazelRcParser("", knownFlagData).parserForCommand(command)
+		tokens := make([]Token, 0, end-start)
+		for i := start; i < end; i++ {
+			tokens = append(tokens, defaultOverrideToken(args[argIndexes[i]], overrides[i].Arg, argIndexes[i]))
+		}
+		var options []Option
+		var targets []string
+		var flagExpectingValue *pendingFlag
+		flagAliases := copyFlagAliases(knownFlagData)
+		if _, err := parser.parseTokenizedArgsOnSingleLineAfterCommand(tokens, &options, &targets, &flagExpectingValue, flagAliases, nil); err != nil {
+			return nil, fmt.Errorf("failed to parse default overrides for %s from %s: %w", command, overrides[start].RcSource, err)
+		}
+		if flagExpectingValue != nil {
+			return nil, fmt.Errorf("failed to parse default overrides for %s from %s: %w", command, overrides[start].RcSource, &MissingFlagValueError{Location: newSpanLocation(nil, flagExpectingValue.token.Span), Flag: stripLeadingDashes(flagExpectingValue.nameWithLeadingDashes)})
+		}
+		if err := parser.normalizeOptionValues(options, nil); err != nil {
+			return nil, fmt.Errorf("failed to parse default overrides for %s from %s: %w", command, overrides[start].RcSource, err)
+		}
+		if _, ok := contents.entries[command]; !ok {
+			contents.entries[command] = make(BazelFlagValues)
+		}
+		for _, option := range options {
+			option.Command = command
+			contents.addOption(option)
+		}
+		contents.lines = append(contents.lines, rcLine{command: command, source: overrides[start].RcSource, args: tokens})
+		start = end
+	}
+	return contents, nil
+}
+
+// defaultOverrideToken makes a token for the argument encoded at the end of the command-line argument encodedArg.
+func defaultOverrideToken(encodedArg string, arg string, argIndex int) Token {
+	token := newArgToken(arg, argIndex)
+	prefixLength := len(encodedArg) - len(arg)
+	token.Span.Start = token.Span.Start.advance(prefixLength)
+	token.Span.End = token.Span.End.advance(prefixLength)
+	return token
+}
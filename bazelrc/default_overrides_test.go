@@ -0,0 +1,113 @@
+package bazelrc
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDefaultOverrides(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "default_overrides")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	flagData := &FlagData{BooleanFlags: map[string]bool{"keep_going": true}}
+	importedFile := newFile(t, testDir, "imported.bazelrc", "build --jobs 10\ncommon --color=yes")
+	workspaceFile := newFile(t, testDir, "workspace.bazelrc", `startup --output_base=/tmp/out
+build --keep_going
+try-import %workspace%/missing.bazelrc
+import %workspace%/imported.bazelrc
+run:repin --action_env=REPIN=true @maven//:repin
+build:ci --jobs=20 \\
+    --config=remote
+build "--copt=-DA B"
+`)
+	// The home file imports a file which the workspace file has already imported, which is only passed once.
+	homeFile := newFile(t, testDir, "home.bazelrc", "build --jobs=4\nimport %workspace%/imported.bazelrc")
+
+	parser := NewBazelRcParser(testDir, flagData)
+	workspaceContents, err := parser.ParsePath(workspaceFile)
+	require.NoError(t, err)
+	homeContents, err := parser.ParsePath(homeFile)
+	require.NoError(t, err)
+
+	encoded := EncodeDefaultOverrides([]string{"--isatty=0", "--terminal_columns=80"}, workspaceContents, homeContents)
+	require.Equal(t, []string{
+		"--rc_source=client",
+		"--default_override=0:common=--isatty=0",
+		"--default_override=0:common=--terminal_columns=80",
+		"--rc_source=" + workspaceFile,
+		"--rc_source=" + importedFile,
+		"--rc_source=" + homeFile,
+		"--default_override=1:build=--keep_going",
+		"--default_override=2:build=--jobs",
+		"--default_override=2:build=10",
+		"--default_override=1:build=--copt=-DA B",
+		"--default_override=1:build:ci=--jobs=20",
+		"--default_override=1:build:ci=--config=remote",
+		"--default_override=2:common=--color=yes",
+		"--default_override=1:run:repin=--action_env=REPIN=true",
+		"--default_override=1:run:repin=@maven//:repin",
+		"--default_override=3:build=--jobs=4",
+		"--default_override=2:build=--jobs",
+		"--default_override=2:build=10",
+		"--default_override=2:common=--color=yes",
+	}, encoded)
+
+	decoded, err := DecodeDefaultOverrides(append([]string{"--max_idle_secs=10"}, encoded...))
+	require.NoError(t, err)
+	require.Len(t, decoded, 15)
+	require.Equal(t, DefaultOverride{RcSource: "client", Command: "common", Arg: "--isatty=0"}, decoded[0])
+	require.Equal(t, DefaultOverride{RcSource: importedFile, Command: "build", Arg: "10"}, decoded[4])
+	require.Equal(t, DefaultOverride{RcSource: workspaceFile, Command: "run:repin", Arg: "@maven//:repin"}, decoded[10])
+	require.Equal(t, DefaultOverride{RcSource: homeFile, Command: "build", Arg: "--jobs=4"}, decoded[11])
+	require.Equal(t, DefaultOverride{RcSource: importedFile, Command: "build", Arg: "10"}, decoded[13])
+
+	parsed, err := ParseDefaultOverrides(flagData, encoded)
+	require.NoError(t, err)
+	require.Equal(t, map[string]BazelFlagValues{
+		"common": {
+			"isatty":           []string{"0"},
+			"terminal_columns": []string{"80"},
+			"color":            []string{"yes", "yes"},
+		},
+		"build": {
+			"keep_going": []string{"true"},
+			"jobs":       []string{"10", "4", "10"},
+			"copt":       []string{"-DA B"},
+		},
+		"build:ci": {
+			"jobs":   []string{"20"},
+			"config": []string{"remote"},
+		},
+		"run:repin": {
+			"action_env": []string{"REPIN=true"},
+		},
+	}, parsed.entries)
+	jobs := parsed.Options()[3]
+	require.Equal(t, "jobs", jobs.Name)
+	require.Equal(t, Span{Start: Position{Column: 28, Offset: 27, ArgIndex: 7}, End: Position{Column: 34, Offset: 33, ArgIndex: 7}}, jobs.NameSpan)
+	require.Equal(t, 8, jobs.ValueSpan.Start.ArgIndex)
+}
+
+func TestDecodeDefaultOverridesErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		args    []string
+		wantErr string
+	}{
+		"missing index separator": {
+			args:    []string{"--rc_source=client", "--default_override=0=--jobs=1"},
+			wantErr: `failed to decode "--default_override=0=--jobs=1": expected --default_override=<index>:<command>=<arg>`,
+		},
+		"unknown rc source": {
+			args:    []string{"--rc_source=client", "--default_override=1:build=--jobs=1"},
+			wantErr: `failed to decode "--default_override=1:build=--jobs=1": "1" isn't the index of an earlier --rc_source`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := DecodeDefaultOverrides(tc.args)
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package bazelrc
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Invocation identifies a command and the configs it's invoked with, e.g. `test --config=ci`.
+type Invocation struct {
+	// Command is the invoked command, e.g. "test".
+	Command string
+	// Configs are the values of the `--config` options on the command line, in order.
+	Configs []string
+}
+
+func (i Invocation) String() string {
+	parts := []string{i.Command}
+	for _, config := range i.Configs {
+		parts = append(parts, "--config="+config)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Invocations returns the invocations which exercise every section of contents: each command with a section, with no configs,
+// and each config with the command it's defined for (or `build`, for configs defined for `common` or `always`).
+// Invocations of several contents are combined, so e.g. the invocations of two versions of a bazelrc file can be compared.
+// The invocations are sorted and deduplicated.
+func Invocations(contents ...*BazelrcContents) []Invocation {
+	byName := make(map[string]Invocation)
+	add := func(invocation Invocation) {
+		byName[invocation.String()] = invocation
+	}
+	for _, c := range contents {
+		for _, option := range c.Options() {
+			command, config, hasConfig := strings.Cut(option.Command, ":")
+			if command == "startup" {
+				continue
+			}
+			if command == "always" || command == "common" {
+				command = "build"
+			}
+			add(Invocation{Command: command})
+			if hasConfig {
+				add(Invocation{Command: command, Configs: []string{config}})
+			}
+		}
+	}
+	invocations := make([]Invocation, 0, len(byName))
+	for _, invocation := range byName {
+		invocations = append(invocations, invocation)
+	}
+	sort.Slice(invocations, func(i, j int) bool { return invocations[i].String() < invocations[j].String() })
+	return invocations
+}
+
+// ChangeKind describes how a flag's effective value changed.
+type ChangeKind int
+
+const (
+	// OptionAdded means the flag is only set after the change.
+	OptionAdded ChangeKind = iota
+	// OptionRemoved means the flag is only set before the change.
+	OptionRemoved
+	// OptionChanged means the flag is set both before and after the change, to different values.
+	OptionChanged
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case OptionAdded:
+		return "added"
+	case OptionRemoved:
+		return "removed"
+	case OptionChanged:
+		return "changed"
+	}
+	return "unknown"
+}
+
+// OptionChange describes a flag whose effective value differs between two configurations.
+type OptionChange struct {
+	Kind ChangeKind
+	// Flag is the name of the flag, without leading dashes.
+	Flag string
+	// Before and After are the effective values of the flag on each side: the last value, or every value if the flag accumulates values.
+	// They are empty if the flag isn't set on that side.
+	Before []string
+	After  []string
+	// BeforeSources and AfterSources are the options which set Before and After, identifying where each value came from.
+	BeforeSources []ResolvedOption
+	AfterSources  []ResolvedOption
+}
+
+// InvocationDiff describes how the effective options of an invocation differ between two configurations.
+type InvocationDiff struct {
+	Invocation Invocation
+	// Changes lists each flag whose effective value differs, sorted by flag.
+	Changes []OptionChange
+	// BeforeErr and AfterErr are the errors resolving the invocation on each side, e.g. an UndefinedConfigError if a config was added or removed.
+	// If either is set, Changes is empty.
+	BeforeErr error
+	AfterErr  error
+}
+
+// DiffInvocations resolves each of invocations with both before and after (e.g. Resolvers of two versions of a bazelrc file), and compares their effective options.
+// Invocations whose effective options are the same, or which can't be resolved on both sides, are omitted.
+func DiffInvocations(before *Resolver, after *Resolver, invocations []Invocation) []InvocationDiff {
+	var diffs []InvocationDiff
+	for _, invocation := range invocations {
+		beforeResolved, beforeErr := before.resolveInvocation(invocation)
+		afterResolved, afterErr := after.resolveInvocation(invocation)
+		if beforeErr != nil && afterErr != nil {
+			continue
+		}
+		diff := InvocationDiff{Invocation: invocation, BeforeErr: beforeErr, AfterErr: afterErr}
+		if beforeErr == nil && afterErr == nil {
+			diff.Changes = diffEffectiveOptions(
+				effectiveOptions(before.knownFlagData, beforeResolved.Options),
+				effectiveOptions(after.knownFlagData, afterResolved.Options),
+			)
+			if len(diff.Changes) == 0 {
+				continue
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// resolveInvocation computes the effective options of invocation.
+func (r *Resolver) resolveInvocation(invocation Invocation) (*ResolvedInvocation, error) {
+	var args []string
+	for _, config := range invocation.Configs {
+		args = append(args, "--config="+config)
+	}
+	parsed, err := ParseCommandLineArgsAfterCommand(r.knownFlagData, args)
+	if err != nil {
+		return nil, err
+	}
+	return r.Resolve(invocation.Command, parsed.Options)
+}
+
+// effectiveOptions groups the options which set the effective value of each flag: the last option for the flag, or every option if the flag accumulates values.
+func effectiveOptions(knownFlagData *FlagData, options []ResolvedOption) map[string][]ResolvedOption {
+	byFlag := make(map[string][]ResolvedOption)
+	for _, option := range canonicalizeOptions(knownFlagData, options) {
+		byFlag[option.Name] = append(byFlag[option.Name], option)
+	}
+	return byFlag
+}
+
+// diffEffectiveOptions compares the effective options of each flag, returning the changes sorted by flag.
+func diffEffectiveOptions(before map[string][]ResolvedOption, after map[string][]ResolvedOption) []OptionChange {
+	var flags []string
+	for flag := range before {
+		flags = append(flags, flag)
+	}
+	for flag := range after {
+		if _, ok := before[flag]; !ok {
+			flags = append(flags, flag)
+		}
+	}
+	sort.Strings(flags)
+
+	values := func(options []ResolvedOption) []string {
+		values := make([]string, 0, len(options))
+		for _, option := range options {
+			values = append(values, option.Value)
+		}
+		return values
+	}
+	var changes []OptionChange
+	for _, flag := range flags {
+		change := OptionChange{
+			Flag:          flag,
+			Before:        values(before[flag]),
+			After:         values(after[flag]),
+			BeforeSources: before[flag],
+			AfterSources:  after[flag],
+		}
+		switch {
+		case len(change.Before) == 0:
+			change.Kind = OptionAdded
+		case len(change.After) == 0:
+			change.Kind = OptionRemoved
+		case slices.Equal(change.Before, change.After):
+			continue
+		default:
+			change.Kind = OptionChanged
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,94 @@
+package bazelrc
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffInvocations(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags:   map[string]bool{"keep_going": true},
+		AllowsMultiple: map[string]bool{"copt": true},
+	}
+	parse := func(fsys fstest.MapFS) (*BazelrcContents, *Resolver) {
+		contents, err := NewBazelRcParser(".", flagData, WithFS(fsys)).ParsePath(".bazelrc")
+		require.NoError(t, err)
+		return contents, NewResolver(contents, flagData, WithPlatformConfig("linux"))
+	}
+	beforeContents, before := parse(fstest.MapFS{
+		".bazelrc": &fstest.MapFile{Data: []byte(`startup --output_base=/tmp/out
+common --color=yes
+build --jobs=10 --copt=-O1
+build:ci --keep_going
+test --test_output=errors
+build:old --jobs=1
+`)},
+	})
+	afterContents, after := parse(fstest.MapFS{
+		".bazelrc": &fstest.MapFile{Data: []byte(`common --color=yes
+build --jobs=10 --copt=-O1
+import %workspace%/tools/ci.bazelrc
+test --test_output=errors
+`)},
+		"tools/ci.bazelrc": &fstest.MapFile{Data: []byte(`build:ci --jobs=20 --copt=-O2
+common:new --color=no
+`)},
+	})
+
+	invocations := Invocations(beforeContents, afterContents)
+	require.Equal(t, []Invocation{
+		{Command: "build"},
+		{Command: "build", Configs: []string{"ci"}},
+		{Command: "build", Configs: []string{"new"}},
+		{Command: "build", Configs: []string{"old"}},
+		{Command: "test"},
+	}, invocations)
+	require.Equal(t, "build --config=ci", invocations[1].String())
+
+	diffs := DiffInvocations(before, after, append(invocations, Invocation{Command: "test", Configs: []string{"ci"}}, Invocation{Command: "test", Configs: []string{"missing"}}))
+	require.Len(t, diffs, 4)
+
+	require.Equal(t, Invocation{Command: "build", Configs: []string{"ci"}}, diffs[0].Invocation)
+	require.NoError(t, diffs[0].BeforeErr)
+	require.NoError(t, diffs[0].AfterErr)
+	require.Equal(t, []ChangeKind{OptionChanged, OptionChanged, OptionRemoved}, []ChangeKind{diffs[0].Changes[0].Kind, diffs[0].Changes[1].Kind, diffs[0].Changes[2].Kind})
+	copt := diffs[0].Changes[0]
+	require.Equal(t, "copt", copt.Flag)
+	require.Equal(t, []string{"-O1"}, copt.Before)
+	require.Equal(t, []string{"-O1", "-O2"}, copt.After)
+	require.Equal(t, "tools/ci.bazelrc", copt.AfterSources[1].Span().Start.File)
+	require.Equal(t, "ci", copt.AfterSources[1].Via[0].Value)
+	jobs := diffs[0].Changes[1]
+	require.Equal(t, OptionChange{
+		Kind:          OptionChanged,
+		Flag:          "jobs",
+		Before:        []string{"10"},
+		After:         []string{"20"},
+		BeforeSources: jobs.BeforeSources,
+		AfterSources:  jobs.AfterSources,
+	}, jobs)
+	require.Equal(t, 3, jobs.BeforeSources[0].Span().Start.Line)
+	require.Equal(t, 1, jobs.AfterSources[0].Span().Start.Line)
+	keepGoing := diffs[0].Changes[2]
+	require.Equal(t, "keep_going", keepGoing.Flag)
+	require.Equal(t, []string{"true"}, keepGoing.Before)
+	require.Empty(t, keepGoing.After)
+	require.Empty(t, keepGoing.AfterSources)
+
+	require.Equal(t, "build --config=new", diffs[1].Invocation.String())
+	require.EqualError(t, diffs[1].BeforeErr, "config value 'new' is not defined in any .rc file")
+	require.NoError(t, diffs[1].AfterErr)
+	require.Empty(t, diffs[1].Changes)
+
+	require.Equal(t, "build --config=old", diffs[2].Invocation.String())
+	require.NoError(t, diffs[2].BeforeErr)
+	require.Error(t, diffs[2].AfterErr)
+
+	require.Equal(t, "test --config=ci", diffs[3].Invocation.String())
+	require.Len(t, diffs[3].Changes, 3)
+
+	require.Empty(t, DiffInvocations(before, before, invocations))
+	require.Equal(t, "added", OptionAdded.String())
+}
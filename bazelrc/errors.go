@@ -0,0 +1,241 @@
+package bazelrc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Location describes where in a tree of bazelrc files (or on a command line) an error was found.
+type Location struct {
+	// Position is the start of the problematic text.
+	// Its File is empty for errors found while parsing a command line.
+	// Its Line is 0 if the error doesn't relate to a particular line, and its Column is 0 if the error doesn't relate to a particular column.
+	Position
+	// End is the position immediately after the problematic text, or the zero Position if the error doesn't relate to a particular span of text.
+	End Position
+	// ImportChain lists the files which were imported to reach File, starting with the top-level file and ending with File.
+	ImportChain []string
+}
+
+func newLocation(importCallStack []string, zeroBaseLineNumber int) Location {
+	if len(importCallStack) == 0 {
+		return Location{Position: Position{ArgIndex: -1}}
+	}
+	return Location{
+		Position: Position{
+			File:     importCallStack[len(importCallStack)-1],
+			Line:     zeroBaseLineNumber + 1,
+			ArgIndex: -1,
+		},
+		ImportChain: slices.Clone(importCallStack),
+	}
+}
+
+// newSpanLocation makes a Location covering span.
+// importCallStack is nil when parsing a command line.
+func newSpanLocation(importCallStack []string, span Span) Location {
+	location := Location{
+		Position: span.Start,
+		End:      span.End,
+	}
+	if len(importCallStack) > 0 {
+		location.ImportChain = slices.Clone(importCallStack)
+	}
+	return location
+}
+
+// describe formats an error message about subject (which is File, unless an error is about a file being imported), prefixing where it happened and suffixing why the file was being read.
+func (l Location) describe(subject string, importChain []string, message string) string {
+	if subject == "" {
+		return message + importReason(importChain)
+	}
+	lineDescription := ""
+	if l.Line > 0 {
+		lineDescription = fmt.Sprintf(" on line %d", l.Line)
+	}
+	return fmt.Sprintf("failed to process %s%s, %s%s", subject, lineDescription, message, importReason(importChain))
+}
+
+// importReason describes how the last file in importChain came to be parsed, if it was reached through more than one import.
+func importReason(importChain []string) string {
+	if len(importChain)-1 > 1 {
+		return fmt.Sprintf(" - File %s imports file %s", importChain[0], strings.Join(importChain[1:], " which imports file "))
+	}
+	return ""
+}
+
+// appendImport returns a copy of importChain with path appended, leaving importChain untouched.
+func appendImport(importChain []string, path string) []string {
+	ret := make([]string, 0, len(importChain)+1)
+	ret = append(ret, importChain...)
+	return append(ret, path)
+}
+
+func (l Location) describeSelf(message string) string {
+	return l.describe(l.File, l.ImportChain, message)
+}
+
+// ParseError is returned for problems which don't have a more specific error type, e.g. malformed import statements or failures to read a file.
+type ParseError struct {
+	Location
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return e.describeSelf(e.Err.Error())
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ImportCycleError is returned when a bazelrc file (transitively) imports itself.
+type ImportCycleError struct {
+	// Location is the import statement which closed the cycle.
+	Location
+	// Cycle lists the files making up the cycle, starting and ending with the file which was imported twice.
+	Cycle []string
+}
+
+func (e *ImportCycleError) Error() string {
+	return e.describe(e.Cycle[len(e.Cycle)-1], appendImport(e.ImportChain, e.Cycle[len(e.Cycle)-1]), "bazelrc file contains import cycle")
+}
+
+// MissingImportError is returned when a file referenced by an `import` statement can't be opened.
+type MissingImportError struct {
+	// Location is the import statement.
+	Location
+	// Path is the path of the file which was being imported, after `%workspace%` substitution.
+	Path string
+	Err  error
+}
+
+func (e *MissingImportError) Error() string {
+	return e.describe(e.Path, appendImport(e.ImportChain, e.Path), fmt.Sprintf("unable to open file: %v", e.Err))
+}
+
+func (e *MissingImportError) Unwrap() error {
+	return e.Err
+}
+
+// DuplicateImportError is reported as a warning when a file is imported more than once, without forming a cycle.
+// Bazel allows this, but it is usually a mistake, as the options in the file are applied each time it is imported.
+type DuplicateImportError struct {
+	// Location is the repeated import statement.
+	Location
+	// Path is the path of the file which was imported, after `%workspace%` substitution.
+	Path string
+	// FirstImport is the import statement which first imported the file, possibly through a different path.
+	FirstImport Location
+}
+
+func (e *DuplicateImportError) Error() string {
+	return e.describeSelf(fmt.Sprintf("file %s was already imported from %s on line %d", e.Path, e.FirstImport.File, e.FirstImport.Line))
+}
+
+// TokenizeError is returned when a line can't be split into tokens, e.g. because of an unmatched quote.
+type TokenizeError struct {
+	Location
+	Err error
+}
+
+func (e *TokenizeError) Error() string {
+	return e.describeSelf(fmt.Sprintf("unable to split line: %v", e.Err))
+}
+
+func (e *TokenizeError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownAbbreviationError is returned when a single-dash flag doesn't correspond to any known flag abbreviation.
+type UnknownAbbreviationError struct {
+	Location
+	// Abbreviation is the abbreviation which was used, without its leading dash (e.g. `z` for `-z`).
+	Abbreviation string
+}
+
+func (e *UnknownAbbreviationError) Error() string {
+	return e.describeSelf(fmt.Sprintf("flag %s wasn't a known abbreviation", e.Abbreviation))
+}
+
+// MissingFlagValueError is returned when a flag which requires a value isn't given one.
+type MissingFlagValueError struct {
+	Location
+	// Flag is the name of the flag, without leading dashes.
+	Flag string
+}
+
+func (e *MissingFlagValueError) Error() string {
+	return e.describeSelf(fmt.Sprintf("value-requiring flag %s didn't have value", e.Flag))
+}
+
+// UnknownFlagError is returned when a token looks like a flag, but can't be interpreted as one.
+type UnknownFlagError struct {
+	Location
+	// Token is the token as it appeared in the input, including any leading dashes.
+	Token string
+	// message describes why Token couldn't be interpreted.
+	message string
+}
+
+func (e *UnknownFlagError) Error() string {
+	return e.describeSelf(e.message)
+}
+
+// Severity describes how serious a Diagnostic is.
+type Severity int
+
+const (
+	// SeverityError is used for problems which would cause Bazel to reject a bazelrc file.
+	SeverityError Severity = iota
+	// SeverityWarning is used for problems which Bazel tolerates, but which are probably mistakes.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Diagnostic is a problem found while parsing.
+type Diagnostic struct {
+	Severity Severity
+	// Location is where the problem was found, copied from Err if it is one of this package's error types.
+	Location Location
+	// Err describes the problem, and is typically one of this package's error types so can be inspected with errors.As.
+	Err error
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %v", d.Severity, d.Err)
+}
+
+// locatedError is implemented by all error types which embed a Location.
+type locatedError interface {
+	errorLocation() Location
+}
+
+func (l Location) errorLocation() Location {
+	return l
+}
+
+func newDiagnostic(severity Severity, err error) Diagnostic {
+	diagnostic := Diagnostic{
+		Severity: severity,
+		Err:      err,
+	}
+	var located locatedError
+	if errors.As(err, &located) {
+		diagnostic.Location = located.errorLocation()
+	}
+	return diagnostic
+}
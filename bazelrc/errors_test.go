@@ -0,0 +1,127 @@
+package bazelrc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrorsAreTyped(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "errors")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	cycleBazelrcFile1 := newFile(t, testDir, "cycle-bazelrc1", fmt.Sprintf("build --foo=bar\nimport %s/cycle-bazelrc2", testDir))
+	cycleBazelrcFile2 := newFile(t, testDir, "cycle-bazelrc2", fmt.Sprintf("import %s/cycle-bazelrc1", testDir))
+	badBazelrcFile := newFile(t, testDir, "bad-bazelrc", "build -z")
+
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{
+			"bool_flag": true,
+		},
+		FlagAbbreviations: map[string]string{
+			"b": "bool_flag",
+			"j": "jobs",
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		input string
+		check func(t *testing.T, err error)
+	}{
+		"import cycle": {
+			input: fmt.Sprintf("import %s", cycleBazelrcFile1),
+			check: func(t *testing.T, err error) {
+				var cycleErr *ImportCycleError
+				require.True(t, errors.As(err, &cycleErr))
+				require.Equal(t, []string{cycleBazelrcFile1, cycleBazelrcFile2, cycleBazelrcFile1}, cycleErr.Cycle)
+				require.Equal(t, cycleBazelrcFile2, cycleErr.File)
+				require.Equal(t, 1, cycleErr.Line)
+				require.Equal(t, []string{"/sample/bazelrc", cycleBazelrcFile1, cycleBazelrcFile2}, cycleErr.ImportChain)
+			},
+		},
+		"missing import": {
+			input: "build --foo=bar\nimport /pathdoesnotexist/to/file",
+			check: func(t *testing.T, err error) {
+				var missingErr *MissingImportError
+				require.True(t, errors.As(err, &missingErr))
+				require.Equal(t, "/pathdoesnotexist/to/file", missingErr.Path)
+				require.Equal(t, "/sample/bazelrc", missingErr.File)
+				require.Equal(t, 2, missingErr.Line)
+				require.True(t, errors.Is(err, os.ErrNotExist))
+			},
+		},
+		"tokenize": {
+			input: "build --foo=bar\n\nbuild --foo=\"bar",
+			check: func(t *testing.T, err error) {
+				var tokenizeErr *TokenizeError
+				require.True(t, errors.As(err, &tokenizeErr))
+				require.Equal(t, "/sample/bazelrc", tokenizeErr.File)
+				require.Equal(t, 3, tokenizeErr.Line)
+			},
+		},
+		"unknown abbreviation in imported file": {
+			input: fmt.Sprintf("import %s", badBazelrcFile),
+			check: func(t *testing.T, err error) {
+				var abbreviationErr *UnknownAbbreviationError
+				require.True(t, errors.As(err, &abbreviationErr))
+				require.Equal(t, "z", abbreviationErr.Abbreviation)
+				require.Equal(t, badBazelrcFile, abbreviationErr.File)
+				require.Equal(t, 1, abbreviationErr.Line)
+				require.Equal(t, []string{"/sample/bazelrc", badBazelrcFile}, abbreviationErr.ImportChain)
+			},
+		},
+		"missing flag value": {
+			input: "build --foo=bar\nbuild --jobs",
+			check: func(t *testing.T, err error) {
+				var missingValueErr *MissingFlagValueError
+				require.True(t, errors.As(err, &missingValueErr))
+				require.Equal(t, "jobs", missingValueErr.Flag)
+				require.Equal(t, 2, missingValueErr.Line)
+			},
+		},
+		"unknown flag": {
+			input: "build -bo",
+			check: func(t *testing.T, err error) {
+				var unknownFlagErr *UnknownFlagError
+				require.True(t, errors.As(err, &unknownFlagErr))
+				require.Equal(t, "-bo", unknownFlagErr.Token)
+				require.Equal(t, 1, unknownFlagErr.Line)
+			},
+		},
+		"malformed import": {
+			input: "import",
+			check: func(t *testing.T, err error) {
+				var parseErr *ParseError
+				require.True(t, errors.As(err, &parseErr))
+				require.Equal(t, 1, parseErr.Line)
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			parser := NewBazelRcParser(testDir, flagData)
+			_, err := parser.Parsefile(strings.NewReader(tc.input), "/sample/bazelrc")
+			require.Error(t, err)
+			tc.check(t, err)
+		})
+	}
+}
+
+func TestCommandLineErrorsAreTyped(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{},
+	}
+	_, err := ParseCommandLineArgsAfterCommand(flagData, []string{"//some:target", "--jobs"})
+	var missingValueErr *MissingFlagValueError
+	require.True(t, errors.As(err, &missingValueErr))
+	require.Equal(t, "jobs", missingValueErr.Flag)
+	require.Equal(t, Location{
+		Position: Position{Column: 1, ArgIndex: 1},
+		End:      Position{Column: 7, Offset: 6, ArgIndex: 1},
+	}, missingValueErr.Location)
+	require.EqualError(t, err, "failed to parse bazel command line: value-requiring flag jobs didn't have value")
+}
@@ -0,0 +1,78 @@
+package bazelrc
+
+import (
+	"fmt"
+)
+
+// bundledExpansions holds the expansions of commonly used expansion flags, as of Bazel 7.
+// It is used for flags whose expansion isn't in FlagData.Expansions.
+var bundledExpansions = map[string][]string{
+	"experimental_spawn_scheduler": {"--internal_spawn_scheduler", "--spawn_strategy=dynamic"},
+	"remote_download_minimal":      {"--remote_download_outputs=minimal"},
+	"remote_download_toplevel":     {"--remote_download_outputs=toplevel"},
+	"remote_download_all":          {"--remote_download_outputs=all"},
+	"java_debug": {
+		"--test_arg=--wrapper_script_flag=--debug",
+		"--test_output=streamed",
+		"--test_strategy=exclusive",
+		"--test_timeout=9999",
+		"--nocache_test_results",
+	},
+	"host_jvm_debug": {"--host_jvm_args=-agentlib:jdwp=transport=dt_socket,server=y,address=5005"},
+}
+
+// BundledExpansions returns a copy of the expansions of commonly used expansion flags which this library knows about, as of Bazel 7.
+func BundledExpansions() map[string][]string {
+	expansions := make(map[string][]string, len(bundledExpansions))
+	for flag, expansion := range bundledExpansions {
+		expansions[flag] = append([]string(nil), expansion...)
+	}
+	return expansions
+}
+
+// expansion returns the flags the named flag expands to, if it's an expansion flag.
+func (d *FlagData) expansion(flagName string) ([]string, bool) {
+	if d != nil {
+		if expansion, ok := d.Expansions[flagName]; ok {
+			return expansion, true
+		}
+	}
+	expansion, ok := bundledExpansions[flagName]
+	return expansion, ok
+}
+
+// expandFlag applies the options which the expansion flag option expands to, in its place.
+// The expanded options are attributed to option's source.
+func (s *resolution) expandFlag(option Option, expansion []string, via []Option) error {
+	if err := checkExpansionCycle(option, via); err != nil {
+		return err
+	}
+	expanded, err := parseExpansion(s.resolver.knownFlagData, expansion)
+	if err != nil {
+		return &ParseError{Location: newSpanLocation(nil, option.Span()), Err: fmt.Errorf("failed to parse expansion of --%s: %w", option.Name, err)}
+	}
+	innerVia := appendVia(via, option)
+	for _, expandedOption := range expanded {
+		expandedOption.Command = option.Command
+		expandedOption.CommandSpan = option.CommandSpan
+		expandedOption.Tokens = option.Tokens
+		expandedOption.NameSpan = option.NameSpan
+		expandedOption.ValueSpan = Span{}
+		if err := s.apply(expandedOption, innerVia); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseExpansion parses the flags an expansion flag expands to.
+func parseExpansion(knownFlagData *FlagData, expansion []string) ([]Option, error) {
+	parsed, err := ParseCommandLineArgsAfterCommand(knownFlagData, expansion)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed.Targets) != 0 || len(parsed.ExecutableArgs) != 0 {
+		return nil, fmt.Errorf("expansion contained non-flags: %v", append(parsed.Targets, parsed.ExecutableArgs...))
+	}
+	return parsed.Options, nil
+}
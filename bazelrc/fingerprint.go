@@ -0,0 +1,111 @@
+package bazelrc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// keyedOverrideFlags lists flags whose values are `name=value` assignments (or just `name`), where a later value for a name overrides any earlier ones,
+// so only the last value for each name takes effect, and the order of values for different names doesn't matter.
+var keyedOverrideFlags = map[string]bool{
+	"action_env":      true,
+	"host_action_env": true,
+	"test_env":        true,
+	"repo_env":        true,
+	"define":          true,
+}
+
+// ConfigurationFingerprint identifies the options of an invocation which affect its outputs, so that invocations which should produce the same outputs can be grouped together.
+type ConfigurationFingerprint struct {
+	// Options are the normalized options which were hashed, e.g. `--compilation_mode=opt`, sorted by flag.
+	Options []string `json:"options"`
+	// Digest is the hex-encoded SHA-256 digest of Options.
+	Digest string `json:"digest"`
+}
+
+// FingerprintConfiguration computes a stable fingerprint of the effective options of an invocation (e.g. the Values of a ResolvedInvocation, or from BuildEventOptions.EffectiveFlagValues).
+// Only options which affect outputs or analysis (those whose Impact is ImpactChangesOutputs or ImpactInvalidatesAnalysisCache) are included, except those which only configure the local machine, like `--disk_cache`,
+// so knownFlagData.EffectTags must be populated; an error is returned if it isn't, as otherwise only Starlark flags would be fingerprinted.
+// Options are normalized so that equivalent invocations have the same fingerprint: values are normalized according to knownFlagData, only the effective values of each flag are included,
+// and for flags like `--action_env` and `--define` only the last value for each name is included, sorted by name.
+// Options which set a flag to its default value according to knownFlagData.DefaultValues (e.g. `--compilation_mode=fastbuild`) are left out, as they're equivalent to not setting the flag.
+func FingerprintConfiguration(knownFlagData *FlagData, values BazelFlagValues) (*ConfigurationFingerprint, error) {
+	if !knownFlagData.hasEffectTags() {
+		return nil, errors.New("failed to fingerprint configuration: no effect tags are known, so the options which affect outputs can't be identified")
+	}
+	var flags []string
+	for flag := range values {
+		if impact := knownFlagData.Impact(flag); !localOnlyFlags[flag] && (impact == ImpactChangesOutputs || impact == ImpactInvalidatesAnalysisCache) {
+			flags = append(flags, flag)
+		}
+	}
+	sort.Strings(flags)
+
+	fingerprint := &ConfigurationFingerprint{Options: []string{}}
+	hash := sha256.New()
+	for _, flag := range flags {
+		var flagValues []string
+		if keyedOverrideFlags[flag] {
+			flagValues = collapseKeyedOverrides(values[flag])
+		} else {
+			flagValues = effectiveValues(knownFlagData, flag, values[flag])
+			if knownFlagData.isDefaultValue(flag, flagValues) {
+				continue
+			}
+		}
+		for _, value := range flagValues {
+			option := fmt.Sprintf("--%s=%s", flag, value)
+			fingerprint.Options = append(fingerprint.Options, option)
+			// Arguments can't contain NUL bytes, so this is unambiguous.
+			fmt.Fprintf(hash, "%s\x00", option)
+		}
+	}
+	fingerprint.Digest = hex.EncodeToString(hash.Sum(nil))
+	return fingerprint, nil
+}
+
+// isDefaultValue returns whether the effective values of flag are just its default value, once normalized.
+// Flags which accumulate values are never considered to have their default value, as their default is usually to have no values.
+func (d *FlagData) isDefaultValue(flag string, effective []string) bool {
+	if d == nil || len(effective) != 1 || d.AllowsMultiple[flag] {
+		return false
+	}
+	defaultValue, ok := d.DefaultValues[flag]
+	return ok && effective[0] == d.comparableValue(flag, defaultValue)
+}
+
+// hasEffectTags returns whether any flag's effect tags are known.
+func (d *FlagData) hasEffectTags() bool {
+	if d == nil {
+		return false
+	}
+	for _, tags := range d.EffectTags {
+		if len(tags) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// collapseKeyedOverrides returns the last of values (which are `name=value` or `name`) for each name, sorted by name.
+func collapseKeyedOverrides(values []string) []string {
+	byName := make(map[string]string)
+	for _, value := range values {
+		name, _, _ := strings.Cut(value, "=")
+		byName[name] = value
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	collapsed := make([]string, 0, len(names))
+	for _, name := range names {
+		collapsed = append(collapsed, byName[name])
+	}
+	return collapsed
+}
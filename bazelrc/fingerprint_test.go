@@ -0,0 +1,123 @@
+package bazelrc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprintConfiguration(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags:   map[string]bool{"keep_going": true, "incompatible_strict_action_env": true},
+		AllowsMultiple: map[string]bool{"copt": true},
+		EffectTags: map[string][]string{
+			"jobs":                           {"host_machine_resource_optimizations", "execution"},
+			"keep_going":                     {"eagerness_to_exit"},
+			"compilation_mode":               {"affects_outputs", "action_command_lines"},
+			"copt":                           {"action_command_lines", "affects_outputs"},
+			"action_env":                     {"action_command_lines"},
+			"define":                         {"changes_inputs", "affects_outputs"},
+			"incompatible_strict_action_env": {"loading_and_analysis"},
+			"disk_cache":                     {"affects_outputs"},
+			"color":                          {"terminal_output"},
+		},
+	}
+
+	fingerprint, err := FingerprintConfiguration(flagData, BazelFlagValues{
+		"jobs":                           {"10"},
+		"keep_going":                     {"true"},
+		"color":                          {"yes"},
+		"disk_cache":                     {"/tmp/cache"},
+		"compilation_mode":               {"dbg", "opt"},
+		"copt":                           {"-O2", "-DA"},
+		"action_env":                     {"PATH", "FOO=1", "BAR=2", "FOO=3"},
+		"define":                         {"b=1", "a=2"},
+		"incompatible_strict_action_env": {"1"},
+		"//pkg:flag":                     {"on"},
+		"remote_header":                  {"x"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"--//pkg:flag=on",
+		"--action_env=BAR=2",
+		"--action_env=FOO=3",
+		"--action_env=PATH",
+		"--compilation_mode=opt",
+		"--copt=-O2",
+		"--copt=-DA",
+		"--define=a=2",
+		"--define=b=1",
+		"--incompatible_strict_action_env=true",
+	}, fingerprint.Options)
+	require.Len(t, fingerprint.Digest, 64)
+
+	equivalent, err := FingerprintConfiguration(flagData, BazelFlagValues{
+		"jobs":                           {"200"},
+		"compilation_mode":               {"opt"},
+		"copt":                           {"-O2", "-DA"},
+		"action_env":                     {"FOO=3", "BAR=2", "PATH"},
+		"define":                         {"a=2", "b=1"},
+		"incompatible_strict_action_env": {"true"},
+		"//pkg:flag":                     {"on"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, fingerprint, equivalent)
+
+	reordered, err := FingerprintConfiguration(flagData, BazelFlagValues{
+		"compilation_mode":               {"opt"},
+		"copt":                           {"-DA", "-O2"},
+		"action_env":                     {"FOO=3", "BAR=2", "PATH"},
+		"define":                         {"a=2", "b=1"},
+		"incompatible_strict_action_env": {"true"},
+		"//pkg:flag":                     {"on"},
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, fingerprint.Digest, reordered.Digest)
+
+	empty, err := FingerprintConfiguration(flagData, BazelFlagValues{"jobs": {"10"}})
+	require.NoError(t, err)
+	require.Empty(t, empty.Options)
+	require.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", empty.Digest)
+}
+
+func TestFingerprintConfigurationDefaultValues(t *testing.T) {
+	flagData := &FlagData{
+		ValueTypes:     map[string]ValueType{"incompatible_strict_action_env": ValueTypeBoolean},
+		DefaultValues:  map[string]string{"compilation_mode": "fastbuild", "incompatible_strict_action_env": "false", "copt": ""},
+		AllowsMultiple: map[string]bool{"copt": true},
+		EffectTags: map[string][]string{
+			"compilation_mode":               {"affects_outputs", "action_command_lines"},
+			"copt":                           {"action_command_lines", "affects_outputs"},
+			"incompatible_strict_action_env": {"loading_and_analysis"},
+		},
+	}
+
+	unset, err := FingerprintConfiguration(flagData, BazelFlagValues{"copt": {"-O2"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"--copt=-O2"}, unset.Options)
+
+	defaults, err := FingerprintConfiguration(flagData, BazelFlagValues{
+		"compilation_mode":               {"opt", "fastbuild"},
+		"incompatible_strict_action_env": {"0"},
+		"copt":                           {"-O2"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, unset, defaults)
+
+	nonDefault, err := FingerprintConfiguration(flagData, BazelFlagValues{"compilation_mode": {"fastbuild", "dbg"}, "copt": {"-O2"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"--compilation_mode=dbg", "--copt=-O2"}, nonDefault.Options)
+}
+
+func TestFingerprintConfigurationWithoutEffectTags(t *testing.T) {
+	for name, flagData := range map[string]*FlagData{
+		"no flag data":   nil,
+		"no effect tags": {BooleanFlags: map[string]bool{"keep_going": true}},
+		"untagged flags": {EffectTags: map[string][]string{"compilation_mode": nil}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := FingerprintConfiguration(flagData, BazelFlagValues{"compilation_mode": {"opt"}, "//pkg:flag": {"on"}})
+			require.EqualError(t, err, "failed to fingerprint configuration: no effect tags are known, so the options which affect outputs can't be identified")
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package bazelrc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// splitFlagAlias splits the value of a `--flag_alias` flag (e.g. `fast=//tools:fast_mode`) into the alias name and the normalized label it stands for.
+func splitFlagAlias(value string) (name string, label string, err error) {
+	name, label, ok := strings.Cut(value, "=")
+	if !ok || name == "" || label == "" {
+		return "", "", fmt.Errorf("flag_alias value %q should be of the form name=label", value)
+	}
+	if IsStarlarkFlag(name) || strings.HasPrefix(name, "-") {
+		return "", "", fmt.Errorf("flag_alias name %q should be a plain flag name", name)
+	}
+	if !IsStarlarkFlag(label) {
+		return "", "", fmt.Errorf("flag_alias %q should alias a Starlark flag, but %q isn't a label", name, label)
+	}
+	return name, NormalizeLabel(label), nil
+}
+
+// recordFlagAliases adds any `--flag_alias` declarations in options to flagAliases, so that they apply to the tokens which follow.
+func recordFlagAliases(options []Option, flagAliases map[string]string, importCallStack []string) error {
+	for _, option := range options {
+		if option.Name != "flag_alias" {
+			continue
+		}
+		name, label, err := splitFlagAlias(option.Value)
+		if err != nil {
+			return &ParseError{Location: newSpanLocation(importCallStack, option.Span()), Err: err}
+		}
+		flagAliases[name] = label
+	}
+	return nil
+}
+
+// declaresFlagAliases returns whether `--flag_alias` options on a line for command (e.g. "build" or "build:ci") declare aliases for the lines which follow.
+// Options in config sections only apply when the config is used, so their aliases are ignored, and `--flag_alias` isn't a startup option.
+func declaresFlagAliases(command string) bool {
+	return command != "startup" && !strings.Contains(command, ":")
+}
+
+// flagAliasesForLine returns the aliases which apply to a line for command (e.g. "build" or "build:ci"): those from FlagData, overridden by those declared on earlier lines for the commands it inherits from, in the order of CommandChain.
+// The returned map is a copy, so aliases declared on the line itself can be added to it without applying to later lines.
+func (s *parseState) flagAliasesForLine(command string) map[string]string {
+	aliases := make(map[string]string, len(s.flagAliases))
+	for name, label := range s.flagAliases {
+		aliases[name] = label
+	}
+	command, _, _ = strings.Cut(command, ":")
+	if command == "startup" {
+		return aliases
+	}
+	for _, inherited := range CommandChain(command) {
+		for name, label := range s.declaredFlagAliases[inherited] {
+			aliases[name] = label
+		}
+	}
+	return aliases
+}
+
+// declareFlagAliases records the aliases declared by options, which were successfully parsed from a line for command, so that they apply to later lines for commands which inherit from it.
+func (s *parseState) declareFlagAliases(command string, options []Option) {
+	if !declaresFlagAliases(command) {
+		return
+	}
+	for _, option := range options {
+		if option.Name != "flag_alias" {
+			continue
+		}
+		// The value was validated when the alias was recorded during parsing.
+		if name, label, err := splitFlagAlias(option.Value); err == nil {
+			if s.declaredFlagAliases[command] == nil {
+				s.declaredFlagAliases[command] = make(map[string]string)
+			}
+			s.declaredFlagAliases[command][name] = label
+		}
+	}
+}
+
+// copyFlagAliases copies the FlagAliases of knownFlagData (which may be nil), normalizing the labels, so that aliases declared while parsing don't modify the caller's map.
+func copyFlagAliases(knownFlagData *FlagData) map[string]string {
+	copied := make(map[string]string)
+	if knownFlagData == nil {
+		return copied
+	}
+	for name, label := range knownFlagData.FlagAliases {
+		copied[name] = NormalizeLabel(label)
+	}
+	return copied
+}
+
+// FlagAliases returns the aliases declared by `--flag_alias` in the parsed files, mapping alias names to normalized labels.
+// Aliases are collected regardless of the command they were declared for, but aliases declared in config sections (e.g. `build:ci`) or on lines with errors aren't included.
+func (c *BazelrcContents) FlagAliases() map[string]string {
+	return c.flagAliases
+}
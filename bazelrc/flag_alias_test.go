@@ -0,0 +1,194 @@
+package bazelrc
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagAliases(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{
+			"jobs":       false,
+			"flag_alias": false,
+		},
+		BuildSettings: map[string]BuildSettingType{
+			"//tools:fast_mode": BuildSettingBool,
+			"//tools:level":     BuildSettingInt,
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		input       string
+		flagAliases map[string]string
+		wantEntries map[string]BazelFlagValues
+		wantAliases map[string]string
+		wantErr     bool
+	}{
+		"boolean alias": {
+			input: `build --flag_alias=fast=//tools:fast_mode
+build:ci --fast
+build:local --nofast //some:target`,
+			wantEntries: map[string]BazelFlagValues{
+				"build":       {"flag_alias": []string{"fast=//tools:fast_mode"}},
+				"build:ci":    {"//tools:fast_mode": []string{"true"}},
+				"build:local": {"//tools:fast_mode": []string{"false"}},
+			},
+			wantAliases: map[string]string{"fast": "//tools:fast_mode"},
+		},
+		"alias with value, declared with a separate value token and used on the same line": {
+			input: "build --flag_alias level=@//tools:level --level=3",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {
+					"flag_alias":    []string{"level=@//tools:level"},
+					"//tools:level": []string{"3"},
+				},
+			},
+			wantAliases: map[string]string{"level": "//tools:level"},
+		},
+		"alias without value for non-boolean build setting": {
+			input:   "build --flag_alias=level=//tools:level --level 3",
+			wantErr: true,
+		},
+		"aliases from flag data": {
+			input:       "build --fast --jobs 10",
+			flagAliases: map[string]string{"fast": "@@//tools:fast_mode"},
+			wantEntries: map[string]BazelFlagValues{
+				"build": {
+					"//tools:fast_mode": []string{"true"},
+					"jobs":              []string{"10"},
+				},
+			},
+			wantAliases: map[string]string{},
+		},
+		"redeclared alias applies from then on": {
+			input: "build --flag_alias=mode=//a:mode --mode=x --flag_alias=mode=//b:mode --mode=y",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {
+					"flag_alias": []string{"mode=//a:mode", "mode=//b:mode"},
+					"//a:mode":   []string{"x"},
+					"//b:mode":   []string{"y"},
+				},
+			},
+			wantAliases: map[string]string{"mode": "//b:mode"},
+		},
+		"alias of native flag": {
+			input:   "build --flag_alias=j=jobs",
+			wantErr: true,
+		},
+		"malformed alias": {
+			input:   "build --flag_alias=fast",
+			wantErr: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			flagData := *flagData
+			flagData.FlagAliases = tc.flagAliases
+			parser := NewBazelRcParser("", &flagData)
+			contents, err := parser.Parsefile(strings.NewReader(tc.input), "/sample/bazelrc")
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantEntries, contents.entries)
+				require.Equal(t, tc.wantAliases, contents.FlagAliases())
+			}
+		})
+	}
+}
+
+func TestFlagAliasesFromImports(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{"flag_alias": false},
+	}
+	testDir, err := os.MkdirTemp("", "aliases")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	newFile(t, testDir, "aliases.bazelrc", "common --flag_alias=fast=//tools:fast_mode")
+	parser := NewBazelRcParser(testDir, flagData)
+	contents, err := parser.Parsefile(strings.NewReader("import %workspace%/aliases.bazelrc\nbuild --fast"), "/sample/bazelrc")
+	require.NoError(t, err)
+	require.Equal(t, []string{"true"}, contents.entries["build"]["//tools:fast_mode"])
+	require.Equal(t, map[string]string{"fast": "//tools:fast_mode"}, contents.FlagAliases())
+}
+
+func TestCommandLineFlagAliases(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{"flag_alias": false},
+		FlagAliases:  map[string]string{"fast": "//tools:fast_mode"},
+	}
+	cmd, err := ParseCommandLineArgsAfterCommand(flagData, []string{"--flag_alias=mode=//tools:mode", "--nofast", "//some:target", "--mode=opt"})
+	require.NoError(t, err)
+	require.Equal(t, BazelFlagValues{
+		"flag_alias":        []string{"mode=//tools:mode"},
+		"//tools:fast_mode": []string{"false"},
+		"//tools:mode":      []string{"opt"},
+	}, cmd.BazelFlags)
+	require.Equal(t, []string{"//some:target"}, cmd.Targets)
+	require.Equal(t, map[string]string{"mode": "//tools:mode"}, cmd.FlagAliases)
+	require.Equal(t, map[string]string{"fast": "//tools:fast_mode"}, flagData.FlagAliases)
+}
+
+func TestFlagAliasScope(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{"flag_alias": false, "jobs": false},
+	}
+
+	for name, tc := range map[string]struct {
+		input           string
+		wantEntries     map[string]BazelFlagValues
+		wantAliases     map[string]string
+		wantDiagnostics int
+	}{
+		"alias applies to commands inheriting from the declaring command": {
+			input: `common --flag_alias=fast=//tools:fast_mode
+test:ci --fast=1`,
+			wantEntries: map[string]BazelFlagValues{
+				"common":  {"flag_alias": []string{"fast=//tools:fast_mode"}},
+				"test:ci": {"//tools:fast_mode": []string{"1"}},
+			},
+			wantAliases: map[string]string{"fast": "//tools:fast_mode"},
+		},
+		"alias doesn't apply to commands which don't inherit from the declaring command": {
+			input: `test --flag_alias=fast=//tools:fast_mode
+build --fast=1`,
+			wantEntries: map[string]BazelFlagValues{
+				"test":  {"flag_alias": []string{"fast=//tools:fast_mode"}},
+				"build": {"fast": []string{"1"}},
+			},
+			wantAliases: map[string]string{"fast": "//tools:fast_mode"},
+		},
+		"alias in config section only applies to its own line": {
+			input: `build:foo --flag_alias=fast=//tools:fast_mode --fast=1
+build --fast=1`,
+			wantEntries: map[string]BazelFlagValues{
+				"build:foo": {
+					"flag_alias":        []string{"fast=//tools:fast_mode"},
+					"//tools:fast_mode": []string{"1"},
+				},
+				"build": {"fast": []string{"1"}},
+			},
+			wantAliases: map[string]string{},
+		},
+		"alias on line with error is ignored": {
+			input: `build --flag_alias=fast=//tools:fast_mode --jobs
+build --fast=1`,
+			wantEntries: map[string]BazelFlagValues{
+				"build": {"fast": []string{"1"}},
+			},
+			wantAliases:     map[string]string{},
+			wantDiagnostics: 1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			parser := NewBazelRcParser("", flagData)
+			contents, diagnostics := parser.ParsefileCollectingDiagnostics(strings.NewReader(tc.input), "/sample/bazelrc")
+			require.Len(t, diagnostics, tc.wantDiagnostics)
+			require.Equal(t, tc.wantEntries, contents.entries)
+			require.Equal(t, tc.wantAliases, contents.FlagAliases())
+		})
+	}
+}
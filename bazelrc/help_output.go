@@ -0,0 +1,298 @@
+package bazelrc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// HelpOption describes one option, as listed in the output of `bazel help <command> --long`.
+type HelpOption struct {
+	// Section is the heading the option was listed under, e.g. "Options that control build execution".
+	Section string
+	// Name is the name of the option, without leading dashes or `[no]` prefix.
+	Name string
+	// Abbreviation is the single-character abbreviation of the option, or empty if it has none.
+	Abbreviation string
+	// RequiresValue is false for boolean and tri-state options (listed as `--[no]name`), and expansion options.
+	RequiresValue bool
+	// TypeDescription is Bazel's description of the option's type, e.g. "a boolean".
+	TypeDescription string
+	// ValueType is the ValueType corresponding to TypeDescription.
+	ValueType ValueType
+	// EnumValues lists the allowed values, if ValueType is ValueTypeEnum.
+	EnumValues []string
+	// DefaultValue is the option's default value, or nil if it has none or it's only described in prose.
+	DefaultValue *string
+	// AllowsMultiple is whether the option may be used multiple times, accumulating values.
+	AllowsMultiple bool
+	// Expansion holds the options this option expands to, or nil if it isn't an expansion option.
+	Expansion []string
+	// EffectTags are the option's effect tags, e.g. "affects_outputs".
+	EffectTags []string
+	// MetadataTags are the option's metadata tags, e.g. "experimental".
+	MetadataTags []string
+	// Description is the option's help text.
+	Description string
+}
+
+// startupOptionsSection is the heading under which help output lists startup options.
+const startupOptionsSection = "Options that appear before the command and are parsed by the client"
+
+// metadataTags are the tags Bazel lists alongside effect tags in help output which describe the option itself, rather than its effect.
+var metadataTags = map[string]bool{
+	"experimental":                          true,
+	"incompatible_change":                   true,
+	"deprecated":                            true,
+	"hidden":                                true,
+	"internal":                              true,
+	"triggered_by_all_incompatible_changes": true,
+	"explicit_in_output_path":               true,
+	"immutable":                             true,
+	"non_configurable":                      true,
+}
+
+var (
+	helpOptionHeaderPattern = regexp.MustCompile(`^  --(\[no\])?([^\s\[(]+)(?: \[-(\w)\])?(?: \((.*)\))?\s*$`)
+	enumTypePattern         = regexp.MustCompile(`^[\w-]+(?:, [\w-]+)* or [\w-]+$`)
+)
+
+// GetHelpOptionsFromBazel returns the options of bazelCommand (e.g. "build") by invoking `bazel help <bazelCommand> --long`.
+// The passed command should be an exec.Cmd which is configured to run bazel in the correct directory with whatever startup args are needed.
+// This function will add a bazel command and flags to that command, and redirect the stdout.
+func GetHelpOptionsFromBazel(command *exec.Cmd, bazelCommand string) ([]HelpOption, error) {
+	var helpOutput bytes.Buffer
+	command.Args = append(command.Args, "help", bazelCommand, "--long")
+	command.Stdout = &helpOutput
+	if err := command.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run bazel help %s --long: %w", bazelCommand, err)
+	}
+	return ParseHelpOutput(&helpOutput)
+}
+
+// ParseHelpOutput parses the output of `bazel help <command> --long`, e.g. as captured in a file.
+func ParseHelpOutput(helpOutput io.Reader) ([]HelpOption, error) {
+	var options []HelpOption
+	var current *HelpOption
+	var description []string
+	inExpansion := false
+	section := ""
+
+	finishOption := func() {
+		if current != nil {
+			current.Description = strings.Join(description, " ")
+			options = append(options, *current)
+		}
+		current = nil
+		description = nil
+		inExpansion = false
+	}
+
+	scanner := bufio.NewScanner(helpOutput)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), " ")
+		switch {
+		case line == "":
+			finishOption()
+		case !strings.HasPrefix(line, " "):
+			finishOption()
+			if strings.HasSuffix(line, ":") {
+				section = strings.TrimSuffix(line, ":")
+			}
+		case strings.HasPrefix(line, "  --"):
+			finishOption()
+			option, err := parseHelpOptionHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse help output on line %d: %w", lineNumber, err)
+			}
+			option.Section = section
+			current = &option
+		case current == nil:
+			// Indented prose which isn't part of an option, e.g. in the command's description.
+		case strings.HasPrefix(line, "      Expands to:"):
+			current.Expansion = append(current.Expansion, strings.Fields(strings.TrimPrefix(line, "      Expands to:"))...)
+			current.RequiresValue = false
+			inExpansion = true
+		case inExpansion && strings.HasPrefix(line, "      "):
+			current.Expansion = append(current.Expansion, strings.Fields(line)...)
+		case strings.HasPrefix(line, "    Tags:"):
+			inExpansion = false
+			for _, tag := range strings.Split(strings.TrimPrefix(line, "    Tags:"), ",") {
+				tag = strings.TrimSpace(tag)
+				if tag == "" {
+					continue
+				}
+				if metadataTags[tag] {
+					current.MetadataTags = append(current.MetadataTags, tag)
+				} else {
+					current.EffectTags = append(current.EffectTags, tag)
+				}
+			}
+		default:
+			inExpansion = false
+			description = append(description, strings.TrimSpace(line))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read help output: %w", err)
+	}
+	finishOption()
+	return options, nil
+}
+
+// parseHelpOptionHeader parses the first line describing an option, e.g. `  --jobs [-j] (an integer; default: "auto")`.
+func parseHelpOptionHeader(line string) (HelpOption, error) {
+	match := helpOptionHeaderPattern.FindStringSubmatch(line)
+	if match == nil {
+		return HelpOption{}, fmt.Errorf("didn't understand option %q", strings.TrimSpace(line))
+	}
+	option := HelpOption{
+		Name:          match[2],
+		Abbreviation:  match[3],
+		RequiresValue: match[1] == "" && match[4] != "",
+	}
+	typeAndDefault := match[4]
+	if typeAndDefault == "" {
+		// Options listed without a type take no value, e.g. expansion options.
+		return option, nil
+	}
+
+	if typeDescription, found := strings.CutSuffix(typeAndDefault, "; may be used multiple times"); found {
+		option.TypeDescription = typeDescription
+		option.AllowsMultiple = true
+	} else if defaultIndex := strings.LastIndex(typeAndDefault, "; default: "); defaultIndex != -1 {
+		option.TypeDescription = typeAndDefault[:defaultIndex]
+		defaultValue := typeAndDefault[defaultIndex+len("; default: "):]
+		if strings.HasPrefix(defaultValue, `"`) && strings.HasSuffix(defaultValue, `"`) && len(defaultValue) >= 2 {
+			defaultValue = defaultValue[1 : len(defaultValue)-1]
+			option.DefaultValue = &defaultValue
+		}
+	} else {
+		option.TypeDescription = typeAndDefault
+	}
+	option.ValueType, option.EnumValues = valueTypeFromHelpDescription(option.TypeDescription)
+	return option, nil
+}
+
+// valueTypeFromHelpDescription guesses a ValueType from Bazel's description of an option's type.
+func valueTypeFromHelpDescription(typeDescription string) (ValueType, []string) {
+	switch {
+	case typeDescription == "a boolean":
+		return ValueTypeBoolean, nil
+	case strings.HasPrefix(typeDescription, "a tri-state"):
+		return ValueTypeTriState, nil
+	case strings.Contains(typeDescription, "HOST_CPUS"):
+		return ValueTypeResourceCount, nil
+	case typeDescription == "an integer":
+		return ValueTypeInteger, nil
+	case strings.Contains(typeDescription, "length of time"):
+		return ValueTypeDuration, nil
+	case strings.Contains(typeDescription, "label"):
+		return ValueTypeLabel, nil
+	case strings.HasPrefix(typeDescription, "comma-separated"):
+		return ValueTypeList, nil
+	case enumTypePattern.MatchString(typeDescription):
+		orIndex := strings.LastIndex(typeDescription, " or ")
+		values := strings.Split(typeDescription[:orIndex], ", ")
+		return ValueTypeEnum, append(values, typeDescription[orIndex+len(" or "):])
+	}
+	return ValueTypeString, nil
+}
+
+// GetFlagDataFromHelpOutputFiles returns a FlagData built from files containing captured output of `bazel help <command> --long`, e.g. one file per command, and optionally of `bazel help startup_options`.
+// This allows flag data to be loaded without invoking bazel.
+func GetFlagDataFromHelpOutputFiles(paths ...string) (*FlagData, error) {
+	flagData := &FlagData{}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open help output file: %w", err)
+		}
+		options, err := ParseHelpOutput(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse help output file %s: %w", path, err)
+		}
+		flagData.AddHelpOptions(options)
+	}
+	return flagData, nil
+}
+
+// AddHelpOptions merges options parsed from help output into d, overwriting anything already known about those options.
+// Startup options (listed in the section headed startupOptionsSection) are merged into d.StartupOptions, which is created if needed.
+func (d *FlagData) AddHelpOptions(options []HelpOption) {
+	var commandOptions, startupOptions []HelpOption
+	for _, option := range options {
+		if option.Section == startupOptionsSection {
+			startupOptions = append(startupOptions, option)
+		} else {
+			commandOptions = append(commandOptions, option)
+		}
+	}
+	d.addHelpOptions(commandOptions)
+	if len(startupOptions) != 0 {
+		if d.StartupOptions == nil {
+			d.StartupOptions = &FlagData{}
+		}
+		d.StartupOptions.addHelpOptions(startupOptions)
+	}
+}
+
+func (d *FlagData) addHelpOptions(options []HelpOption) {
+	if d.BooleanFlags == nil {
+		d.BooleanFlags = make(map[string]bool)
+	}
+	if d.FlagAbbreviations == nil {
+		d.FlagAbbreviations = make(map[string]string)
+	}
+	if d.ValueTypes == nil {
+		d.ValueTypes = make(map[string]ValueType)
+	}
+	if d.EnumValues == nil {
+		d.EnumValues = make(map[string][]string)
+	}
+	if d.DefaultValues == nil {
+		d.DefaultValues = make(map[string]string)
+	}
+	if d.AllowsMultiple == nil {
+		d.AllowsMultiple = make(map[string]bool)
+	}
+	if d.EffectTags == nil {
+		d.EffectTags = make(map[string][]string)
+	}
+	if d.MetadataTags == nil {
+		d.MetadataTags = make(map[string][]string)
+	}
+	if d.Expansions == nil {
+		d.Expansions = make(map[string][]string)
+	}
+
+	for _, option := range options {
+		d.BooleanFlags[option.Name] = !option.RequiresValue
+		if option.Abbreviation != "" {
+			d.FlagAbbreviations[option.Abbreviation] = option.Name
+		}
+		if option.ValueType != "" {
+			d.ValueTypes[option.Name] = option.ValueType
+		}
+		if option.EnumValues != nil {
+			d.EnumValues[option.Name] = option.EnumValues
+		}
+		if option.DefaultValue != nil {
+			d.DefaultValues[option.Name] = *option.DefaultValue
+		}
+		if option.Expansion != nil {
+			d.Expansions[option.Name] = option.Expansion
+		}
+		d.AllowsMultiple[option.Name] = option.AllowsMultiple
+		d.EffectTags[option.Name] = option.EffectTags
+		d.MetadataTags[option.Name] = option.MetadataTags
+	}
+}
@@ -0,0 +1,145 @@
+package bazelrc
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/rules_go/go/runfiles"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHelpOutput(t *testing.T) {
+	helpOutputPath, err := runfiles.Rlocation("bazelrc_parser_go/bazelrc/testdata/help_build_long.txt")
+	require.NoError(t, err)
+	flagData, err := GetFlagDataFromHelpOutputFiles(helpOutputPath)
+	require.NoError(t, err)
+
+	stringPointer := func(s string) *string { return &s }
+	byName := make(map[string]HelpOption)
+	for _, option := range mustParseHelpOutputFile(t, helpOutputPath) {
+		byName[option.Name] = option
+	}
+	require.Len(t, byName, 16)
+
+	for name, want := range map[string]HelpOption{
+		"autodetect_server_javabase": {
+			Section:         "Options that appear before the command and are parsed by the client",
+			Name:            "autodetect_server_javabase",
+			TypeDescription: "a boolean",
+			ValueType:       ValueTypeBoolean,
+			DefaultValue:    stringPointer("true"),
+			EffectTags:      []string{"affects_outputs", "loses_incremental_state"},
+			Description:     "When --noautodetect_server_javabase is passed, Bazel does not fall back to the local JDK for running the bazel server and instead exits.",
+		},
+		"bazelrc": {
+			Section:         "Options that appear before the command and are parsed by the client",
+			Name:            "bazelrc",
+			RequiresValue:   true,
+			TypeDescription: "a string",
+			ValueType:       ValueTypeString,
+			EffectTags:      []string{"changes_inputs"},
+			Description:     "The location of the user .bazelrc file containing default values of Bazel options.",
+		},
+		"experimental_spawn_scheduler": {
+			Section:      "Options that control build execution",
+			Name:         "experimental_spawn_scheduler",
+			Expansion:    []string{"--internal_spawn_scheduler", "--spawn_strategy=dynamic"},
+			EffectTags:   []string{"execution"},
+			MetadataTags: []string{"experimental"},
+			Description:  "Enable dynamic execution by running actions locally and remotely in parallel.",
+		},
+		"jobs": {
+			Section:         "Options that control build execution",
+			Name:            "jobs",
+			Abbreviation:    "j",
+			RequiresValue:   true,
+			TypeDescription: `an integer, or a keyword ("auto", "HOST_CPUS", "HOST_RAM"), optionally followed by an operation ([-|*]<float>) eg. "auto", "HOST_CPUS*.5"`,
+			ValueType:       ValueTypeResourceCount,
+			DefaultValue:    stringPointer("auto"),
+			EffectTags:      []string{"host_machine_resource_optimizations", "execution"},
+			Description:     `The number of concurrent jobs to run. Takes an integer, or a keyword ("auto", "HOST_CPUS", "HOST_RAM"), optionally followed by an operation ([-|*]<float>) eg. "auto", "HOST_CPUS*.5".`,
+		},
+		"compilation_mode": {
+			Section:         "Options that configure the toolchain used for action execution",
+			Name:            "compilation_mode",
+			Abbreviation:    "c",
+			RequiresValue:   true,
+			TypeDescription: "fastbuild, dbg or opt",
+			ValueType:       ValueTypeEnum,
+			EnumValues:      []string{"fastbuild", "dbg", "opt"},
+			DefaultValue:    stringPointer("fastbuild"),
+			EffectTags:      []string{"affects_outputs", "action_command_lines"},
+			MetadataTags:    []string{"explicit_in_output_path"},
+			Description:     "Specify the mode the binary will be built in. Values: 'fastbuild', 'dbg', 'opt'.",
+		},
+		"spawn_strategy": {
+			Section:         "Options that control build execution",
+			Name:            "spawn_strategy",
+			RequiresValue:   true,
+			TypeDescription: "comma-separated list of options",
+			ValueType:       ValueTypeList,
+			AllowsMultiple:  true,
+			EffectTags:      []string{"execution"},
+			Description:     "Specify how spawn actions are executed by default.",
+		},
+		"experimental_convenience_symlinks_with_a_very_long_name_that_wraps": {
+			Section:     "Options that affect the verbosity, format or location of logging",
+			Name:        "experimental_convenience_symlinks_with_a_very_long_name_that_wraps",
+			Expansion:   []string{"--experimental_convenience_symlinks=normal", "--experimental_convenience_symlinks_bep_event"},
+			EffectTags:  []string{"affects_outputs"},
+			Description: "",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, want, byName[name])
+		})
+	}
+
+	for name, want := range map[string]ValueType{
+		"crosstool_top":                         ValueTypeLabel,
+		"remote_timeout":                        ValueTypeDuration,
+		"experimental_remote_cache_compression": ValueTypeTriState,
+		"test_tag_filters":                      ValueTypeList,
+		"define":                                ValueTypeString,
+	} {
+		require.Equal(t, want, flagData.ValueTypes[name], name)
+	}
+	require.Equal(t, "60s", flagData.DefaultValues["remote_timeout"])
+	require.Equal(t, "", flagData.DefaultValues["test_tag_filters"])
+	require.NotContains(t, flagData.DefaultValues, "bazelrc")
+	require.Equal(t, []string{"--internal_spawn_scheduler", "--spawn_strategy=dynamic"}, flagData.Expansions["experimental_spawn_scheduler"])
+	require.True(t, flagData.AllowsMultiple["copt"])
+	require.False(t, flagData.AllowsMultiple["jobs"])
+	require.Equal(t, []string{"incompatible_change"}, flagData.MetadataTags["incompatible_strict_action_env"])
+	require.Equal(t, map[string]string{"j": "jobs", "k": "keep_going", "c": "compilation_mode"}, flagData.FlagAbbreviations)
+	require.True(t, flagData.BooleanFlags["experimental_remote_cache_compression"])
+	require.True(t, flagData.BooleanFlags["experimental_spawn_scheduler"])
+	require.False(t, flagData.BooleanFlags["copt"])
+
+	parser := NewBazelRcParser("", flagData)
+	contents, err := parser.Parsefile(strings.NewReader("build -c OPT -k --jobs=HOST_CPUS*.5 --noexperimental_remote_cache_compression --remote_timeout=0120s"), "/sample/bazelrc")
+	require.NoError(t, err)
+	require.Equal(t, BazelFlagValues{
+		"compilation_mode":                      []string{"opt"},
+		"keep_going":                            []string{"true"},
+		"jobs":                                  []string{"HOST_CPUS*0.5"},
+		"experimental_remote_cache_compression": []string{"no"},
+		"remote_timeout":                        []string{"120s"},
+	}, contents.entries["build"])
+}
+
+func mustParseHelpOutputFile(t *testing.T, path string) []HelpOption {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	options, err := ParseHelpOutput(file)
+	require.NoError(t, err)
+	return options
+}
+
+func TestParseHelpOutputErrors(t *testing.T) {
+	_, err := ParseHelpOutput(strings.NewReader("Options:\n  --(broken\n"))
+	require.EqualError(t, err, `failed to parse help output on line 2: didn't understand option "--(broken"`)
+}
@@ -0,0 +1,98 @@
+package bazelrc
+
+import (
+	"strings"
+)
+
+// Impact classifies the effect of changing a flag's value on Bazel's caches, based on the flag's effect tags.
+// Impacts are ordered from least to most disruptive, except ImpactUnknown.
+type Impact int
+
+const (
+	// ImpactNoOp means changing the flag has no effect, e.g. because it's deprecated and ignored.
+	ImpactNoOp Impact = iota
+	// ImpactUIOnly means changing the flag only affects what Bazel reports, e.g. to the terminal or to the Build Event Protocol.
+	ImpactUIOnly
+	// ImpactExecutionOnly means changing the flag affects how actions are run (e.g. `--jobs`), but not their outputs, so cached outputs are still used.
+	ImpactExecutionOnly
+	// ImpactChangesOutputs means changing the flag changes the inputs, command lines or outputs of actions, and so their keys in local and remote caches.
+	// Such flags may also invalidate the analysis cache, but are only classified as doing so if they're tagged accordingly.
+	ImpactChangesOutputs
+	// ImpactInvalidatesAnalysisCache means changing the flag changes the build configuration or how BUILD files are loaded, so Bazel discards its analysis cache.
+	ImpactInvalidatesAnalysisCache
+	// ImpactUnknown means the flag's effect isn't known, because it has no effect tags (or only the `unknown` tag).
+	ImpactUnknown
+)
+
+func (i Impact) String() string {
+	switch i {
+	case ImpactNoOp:
+		return "no-op"
+	case ImpactUIOnly:
+		return "UI only"
+	case ImpactExecutionOnly:
+		return "execution only"
+	case ImpactChangesOutputs:
+		return "changes action outputs"
+	case ImpactInvalidatesAnalysisCache:
+		return "invalidates analysis cache"
+	case ImpactUnknown:
+		return "unknown"
+	}
+	return "invalid"
+}
+
+// impactOfEffectTags maps each of Bazel's effect tags (from option_filters.proto) to the impact of changing a flag with the tag.
+// The `unknown` tag isn't listed, as it says nothing about the flag's impact.
+var impactOfEffectTags = map[string]Impact{
+	"no_op":                               ImpactNoOp,
+	"terminal_output":                     ImpactUIOnly,
+	"bazel_monitoring":                    ImpactUIOnly,
+	"execution":                           ImpactExecutionOnly,
+	"host_machine_resource_optimizations": ImpactExecutionOnly,
+	"eagerness_to_exit":                   ImpactExecutionOnly,
+	"test_runner":                         ImpactExecutionOnly,
+	"affects_outputs":                     ImpactChangesOutputs,
+	"action_command_lines":                ImpactChangesOutputs,
+	"changes_inputs":                      ImpactChangesOutputs,
+	"loading_and_analysis":                ImpactInvalidatesAnalysisCache,
+	"build_file_semantics":                ImpactInvalidatesAnalysisCache,
+	"bazel_internal_configuration":        ImpactInvalidatesAnalysisCache,
+	"loses_incremental_state":             ImpactInvalidatesAnalysisCache,
+}
+
+// Impact classifies the effect of changing the named flag (without leading dashes), using the most disruptive of its EffectTags.
+// Starlark flags are build settings, which are part of the build configuration, so changing them invalidates the analysis cache.
+func (d *FlagData) Impact(flagName string) Impact {
+	if IsStarlarkFlag(flagName) {
+		return ImpactInvalidatesAnalysisCache
+	}
+	if d == nil {
+		return ImpactUnknown
+	}
+	impact := ImpactUnknown
+	for _, tag := range d.EffectTags[flagName] {
+		tagImpact, ok := impactOfEffectTags[strings.ToLower(tag)]
+		if ok && (impact == ImpactUnknown || tagImpact > impact) {
+			impact = tagImpact
+		}
+	}
+	return impact
+}
+
+// ClassifiedDifference is a flag whose effective values differ between two sets of options, with the impact of the difference.
+type ClassifiedDifference struct {
+	FlagValueMismatch
+	Impact Impact
+}
+
+// ClassifyDifferences compares the effective values of each flag in before and after (e.g. the Values of two ResolvedInvocations), as CompareFlagValues does,
+// and classifies the impact of each difference using knownFlagData.EffectTags, e.g. to warn before switching configs would discard the analysis cache.
+// The differences are sorted by flag.
+func ClassifyDifferences(knownFlagData *FlagData, before BazelFlagValues, after BazelFlagValues) []ClassifiedDifference {
+	var differences []ClassifiedDifference
+	for _, mismatch := range CompareFlagValues(knownFlagData, before, after) {
+		differences = append(differences, ClassifiedDifference{FlagValueMismatch: mismatch, Impact: knownFlagData.Impact(mismatch.Flag)})
+	}
+	return differences
+}
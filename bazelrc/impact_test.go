@@ -0,0 +1,64 @@
+package bazelrc
+
+import (
+	"testing"
+
+	"github.com/bazelbuild/rules_go/go/runfiles"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpact(t *testing.T) {
+	helpOutput, err := runfiles.Rlocation("bazelrc_parser_go/bazelrc/testdata/help_build_long.txt")
+	require.NoError(t, err)
+	flagData, err := GetFlagDataFromHelpOutputFiles(helpOutput)
+	require.NoError(t, err)
+	flagData.EffectTags["color"] = []string{"terminal_output"}
+	flagData.EffectTags["deprecated_flag"] = []string{"no_op"}
+	flagData.EffectTags["upper_case_flag"] = []string{"BAZEL_MONITORING"}
+
+	for flag, want := range map[string]Impact{
+		"deprecated_flag":                       ImpactNoOp,
+		"color":                                 ImpactUIOnly,
+		"upper_case_flag":                       ImpactUIOnly,
+		"jobs":                                  ImpactExecutionOnly,
+		"keep_going":                            ImpactExecutionOnly,
+		"compilation_mode":                      ImpactChangesOutputs,
+		"define":                                ImpactChangesOutputs,
+		"crosstool_top":                         ImpactInvalidatesAnalysisCache,
+		"incompatible_strict_action_env":        ImpactInvalidatesAnalysisCache,
+		"//pkg:flag":                            ImpactInvalidatesAnalysisCache,
+		"experimental_remote_cache_compression": ImpactUnknown,
+		"not_a_known_flag":                      ImpactUnknown,
+	} {
+		t.Run(flag, func(t *testing.T) {
+			require.Equal(t, want, flagData.Impact(flag))
+		})
+	}
+	require.Equal(t, ImpactUnknown, (*FlagData)(nil).Impact("jobs"))
+	require.Equal(t, "invalidates analysis cache", ImpactInvalidatesAnalysisCache.String())
+}
+
+func TestClassifyDifferences(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags:   map[string]bool{"keep_going": true},
+		AllowsMultiple: map[string]bool{"copt": true},
+		EffectTags: map[string][]string{
+			"jobs":       {"host_machine_resource_optimizations", "execution"},
+			"keep_going": {"eagerness_to_exit"},
+			"copt":       {"action_command_lines", "affects_outputs"},
+			"color":      {"terminal_output"},
+			"platforms":  {"affects_outputs", "changes_inputs", "loading_and_analysis"},
+		},
+	}
+	differences := ClassifyDifferences(flagData,
+		BazelFlagValues{"jobs": {"10"}, "keep_going": {"1"}, "copt": {"-O1"}, "color": {"yes"}},
+		BazelFlagValues{"jobs": {"20"}, "keep_going": {"true"}, "copt": {"-O1", "-O2"}, "platforms": {"//:linux"}, "//pkg:flag": {"on"}},
+	)
+	require.Equal(t, []ClassifiedDifference{
+		{FlagValueMismatch: FlagValueMismatch{Flag: "//pkg:flag", Want: []string{}, Got: []string{"on"}}, Impact: ImpactInvalidatesAnalysisCache},
+		{FlagValueMismatch: FlagValueMismatch{Flag: "color", Want: []string{"yes"}, Got: []string{}}, Impact: ImpactUIOnly},
+		{FlagValueMismatch: FlagValueMismatch{Flag: "copt", Want: []string{"-O1"}, Got: []string{"-O1", "-O2"}}, Impact: ImpactChangesOutputs},
+		{FlagValueMismatch: FlagValueMismatch{Flag: "jobs", Want: []string{"10"}, Got: []string{"20"}}, Impact: ImpactExecutionOnly},
+		{FlagValueMismatch: FlagValueMismatch{Flag: "platforms", Want: []string{}, Got: []string{"//:linux"}}, Impact: ImpactInvalidatesAnalysisCache},
+	}, differences)
+}
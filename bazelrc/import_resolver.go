@@ -0,0 +1,121 @@
+package bazelrc
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ImportResolver locates and opens bazelrc files, including those referenced by `import` and `try-import` statements.
+type ImportResolver interface {
+	// Resolve turns a path as written in an import statement (e.g. `%workspace%/tools/ci.bazelrc`) into the path of the file to open.
+	// importingFile is the resolved path of the file containing the import statement, or empty when resolving a top-level file.
+	Resolve(importPath string, importingFile string) (string, error)
+	// Open opens a file at a path returned by Resolve.
+	Open(resolvedPath string) (io.ReadCloser, error)
+}
+
+// NewOSImportResolver makes an ImportResolver which reads files from the local filesystem, replacing `%workspace%` with workspaceDirectory.
+// This is what a BazelRcParser uses if no other ImportResolver is configured.
+func NewOSImportResolver(workspaceDirectory string) ImportResolver {
+	return &osImportResolver{workspaceDirectory: workspaceDirectory}
+}
+
+type osImportResolver struct {
+	workspaceDirectory string
+}
+
+func (r *osImportResolver) Resolve(importPath string, importingFile string) (string, error) {
+	return strings.ReplaceAll(importPath, "%workspace%", r.workspaceDirectory), nil
+}
+
+func (r *osImportResolver) Open(resolvedPath string) (io.ReadCloser, error) {
+	return os.Open(resolvedPath)
+}
+
+// NewFSImportResolver makes an ImportResolver which reads files from fsys, replacing `%workspace%` with workspaceDirectory, which is a path within fsys (e.g. ".").
+// As fs.FS paths are always unrooted, absolute paths in import statements are interpreted relative to the root of fsys,
+// so e.g. an fs.FS holding a snapshot of a whole filesystem can resolve `import /etc/bazel.bazelrc`.
+func NewFSImportResolver(fsys fs.FS, workspaceDirectory string) ImportResolver {
+	return &fsImportResolver{fsys: fsys, workspaceDirectory: workspaceDirectory}
+}
+
+type fsImportResolver struct {
+	fsys               fs.FS
+	workspaceDirectory string
+}
+
+func (r *fsImportResolver) Resolve(importPath string, importingFile string) (string, error) {
+	resolved := path.Clean(strings.ReplaceAll(importPath, "%workspace%", r.workspaceDirectory))
+	resolved = strings.TrimPrefix(resolved, "/")
+	if resolved == "" {
+		resolved = "."
+	}
+	if !fs.ValidPath(resolved) {
+		return "", &fs.PathError{Op: "resolve", Path: importPath, Err: fs.ErrInvalid}
+	}
+	return resolved, nil
+}
+
+func (r *fsImportResolver) Open(resolvedPath string) (io.ReadCloser, error) {
+	return r.fsys.Open(resolvedPath)
+}
+
+// FileIdentity identifies a file, so that different paths referring to the same file can be recognised.
+type FileIdentity struct {
+	// CanonicalPath is the cleaned path of the file, with symlinks resolved if the resolver supports them.
+	CanonicalPath string
+	// fileInfo is used to compare device and inode numbers (or their platform equivalents), if available.
+	fileInfo os.FileInfo
+}
+
+// SameFile returns whether i and other identify the same file.
+func (i FileIdentity) SameFile(other FileIdentity) bool {
+	if i.fileInfo != nil && other.fileInfo != nil {
+		return os.SameFile(i.fileInfo, other.fileInfo)
+	}
+	return i.CanonicalPath == other.CanonicalPath
+}
+
+// FileIdentifier may optionally be implemented by an ImportResolver, to allow the parser to recognise when different paths refer to the same file.
+// This is used to detect import cycles and duplicate imports.
+// For ImportResolvers which don't implement it, paths are compared after cleaning.
+type FileIdentifier interface {
+	// Identify returns the identity of the file at a path returned by Resolve.
+	Identify(resolvedPath string) (FileIdentity, error)
+}
+
+// Identify makes paths absolute, resolves symlinks, and uses device and inode numbers to identify files where possible.
+// Files which don't exist are identified by their cleaned absolute path.
+func (r *osImportResolver) Identify(resolvedPath string) (FileIdentity, error) {
+	canonicalPath, err := filepath.Abs(resolvedPath)
+	if err != nil {
+		return FileIdentity{}, err
+	}
+	if withoutSymlinks, err := filepath.EvalSymlinks(canonicalPath); err == nil {
+		canonicalPath = withoutSymlinks
+	}
+	identity := FileIdentity{CanonicalPath: canonicalPath}
+	if fileInfo, err := os.Stat(canonicalPath); err == nil {
+		identity.fileInfo = fileInfo
+	}
+	return identity, nil
+}
+
+// Identify cleans paths; fs.FS doesn't expose symlinks or inode numbers.
+func (r *fsImportResolver) Identify(resolvedPath string) (FileIdentity, error) {
+	return FileIdentity{CanonicalPath: path.Clean(resolvedPath)}, nil
+}
+
+// identifyFile returns the identity of resolvedPath, using resolver if it supports identifying files.
+func identifyFile(resolver ImportResolver, resolvedPath string) FileIdentity {
+	if identifier, ok := resolver.(FileIdentifier); ok {
+		if identity, err := identifier.Identify(resolvedPath); err == nil {
+			return identity
+		}
+	}
+	return FileIdentity{CanonicalPath: filepath.Clean(resolvedPath)}
+}
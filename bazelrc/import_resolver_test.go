@@ -0,0 +1,219 @@
+package bazelrc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"repo/.bazelrc": &fstest.MapFile{Data: []byte(`build --foo=root
+import %workspace%/tools/ci.bazelrc
+try-import %workspace%/user.bazelrc
+try-import /etc/bazel.bazelrc
+`)},
+		"repo/tools/ci.bazelrc": &fstest.MapFile{Data: []byte("build:ci --jobs=10")},
+		"etc/bazel.bazelrc":     &fstest.MapFile{Data: []byte("build --system=true")},
+	}
+
+	for name, tc := range map[string]struct {
+		workspace string
+		path      string
+	}{
+		"workspace-relative top-level path": {
+			workspace: "repo",
+			path:      "%workspace%/.bazelrc",
+		},
+		"absolute paths are rooted at the FS": {
+			workspace: "/repo",
+			path:      "/repo/.bazelrc",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			parser := NewBazelRcParser(tc.workspace, &FlagData{}, WithFS(fsys))
+			contents, err := parser.ParsePath(tc.path)
+			require.NoError(t, err)
+			require.Equal(t, map[string]BazelFlagValues{
+				"build": {
+					"foo":    []string{"root"},
+					"system": []string{"true"},
+				},
+				"build:ci": {
+					"jobs": []string{"10"},
+				},
+			}, contents.entries)
+			require.Equal(t, "repo/tools/ci.bazelrc", contents.Options()[1].NameSpan.Start.File)
+		})
+	}
+}
+
+func TestParseFromFSMissingImport(t *testing.T) {
+	fsys := fstest.MapFS{
+		".bazelrc": &fstest.MapFile{Data: []byte("import %workspace%/missing.bazelrc")},
+	}
+	parser := NewBazelRcParser(".", &FlagData{}, WithFS(fsys))
+	_, err := parser.ParsePath(".bazelrc")
+	var missingErr *MissingImportError
+	require.ErrorAs(t, err, &missingErr)
+	require.Equal(t, "missing.bazelrc", missingErr.Path)
+	require.True(t, errors.Is(err, fs.ErrNotExist))
+
+	_, err = parser.ParsePath("does-not-exist.bazelrc")
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	require.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+// overlayResolver serves some files from memory, and delegates the rest.
+type overlayResolver struct {
+	files    map[string]string
+	delegate ImportResolver
+	resolved [][2]string
+}
+
+func (r *overlayResolver) Resolve(importPath string, importingFile string) (string, error) {
+	r.resolved = append(r.resolved, [2]string{importPath, importingFile})
+	return r.delegate.Resolve(importPath, importingFile)
+}
+
+func (r *overlayResolver) Open(resolvedPath string) (io.ReadCloser, error) {
+	if contents, ok := r.files[resolvedPath]; ok {
+		return io.NopCloser(strings.NewReader(contents)), nil
+	}
+	return r.delegate.Open(resolvedPath)
+}
+
+func TestParseWithImportResolver(t *testing.T) {
+	resolver := &overlayResolver{
+		files: map[string]string{
+			"/ws/.bazelrc":   "import %workspace%/ci.bazelrc\nbuild --foo=bar",
+			"/ws/ci.bazelrc": "build:ci --jobs=10",
+		},
+		delegate: NewOSImportResolver("/ws"),
+	}
+	parser := NewBazelRcParser("/ws", &FlagData{}, WithImportResolver(resolver))
+	contents, err := parser.ParsePath("%workspace%/.bazelrc")
+	require.NoError(t, err)
+	require.Equal(t, map[string]BazelFlagValues{
+		"build": {
+			"foo": []string{"bar"},
+		},
+		"build:ci": {
+			"jobs": []string{"10"},
+		},
+	}, contents.entries)
+	require.Equal(t, [][2]string{
+		{"%workspace%/.bazelrc", ""},
+		{"%workspace%/ci.bazelrc", "/ws/.bazelrc"},
+	}, resolver.resolved)
+}
+
+func TestConsultedFiles(t *testing.T) {
+	sha256Of := func(contents string) string {
+		digest := sha256.Sum256([]byte(contents))
+		return hex.EncodeToString(digest[:])
+	}
+
+	rootContents := `import %workspace%/tools/ci.bazelrc
+try-import %workspace%/user.bazelrc
+import %workspace%/tools/ci.bazelrc
+try-import %workspace%/user.bazelrc`
+	ciContents := "build:ci --jobs=10"
+	fsys := fstest.MapFS{
+		".bazelrc":         &fstest.MapFile{Data: []byte(rootContents)},
+		"tools/ci.bazelrc": &fstest.MapFile{Data: []byte(ciContents)},
+		"tools/unused.rc":  &fstest.MapFile{Data: []byte("build --unused=true")},
+	}
+	parser := NewBazelRcParser(".", &FlagData{}, WithFS(fsys))
+	contents, err := parser.ParsePath(".bazelrc")
+	require.NoError(t, err)
+	require.Equal(t, []ConsultedFile{
+		{Path: ".bazelrc", Exists: true, SHA256: sha256Of(rootContents)},
+		{Path: "tools/ci.bazelrc", Exists: true, SHA256: sha256Of(ciContents)},
+		{Path: "user.bazelrc", Exists: false},
+	}, contents.ConsultedFiles())
+
+	digest := contents.ConsultedFilesDigest()
+	reparsed, err := parser.ParsePath(".bazelrc")
+	require.NoError(t, err)
+	require.Equal(t, digest, reparsed.ConsultedFilesDigest())
+
+	// Creating a previously-missing try-import invalidates the digest.
+	fsys["user.bazelrc"] = &fstest.MapFile{Data: []byte("")}
+	withUserFile, err := parser.ParsePath(".bazelrc")
+	require.NoError(t, err)
+	require.Equal(t, ConsultedFile{Path: "user.bazelrc", Exists: true, SHA256: sha256Of("")}, withUserFile.ConsultedFiles()[2])
+	require.NotEqual(t, digest, withUserFile.ConsultedFilesDigest())
+
+	// Changing an imported file invalidates the digest.
+	delete(fsys, "user.bazelrc")
+	fsys["tools/ci.bazelrc"] = &fstest.MapFile{Data: []byte("build:ci --jobs=20")}
+	withChangedImport, err := parser.ParsePath(".bazelrc")
+	require.NoError(t, err)
+	require.NotEqual(t, digest, withChangedImport.ConsultedFilesDigest())
+}
+
+func TestImportCyclesAreDetectedByFileIdentity(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "identity")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	fileA := newFile(t, testDir, "a.bazelrc", "import %workspace%/link.bazelrc")
+	fileB := newFile(t, testDir, "b.bazelrc", fmt.Sprintf("import %s/./a.bazelrc", testDir))
+	require.NoError(t, os.Symlink(fileB, filepath.Join(testDir, "link.bazelrc")))
+
+	parser := NewBazelRcParser(testDir, &FlagData{})
+	_, err = parser.ParsePath(fileA)
+	var cycleErr *ImportCycleError
+	require.ErrorAs(t, err, &cycleErr)
+	require.Equal(t, []string{fileA, filepath.Join(testDir, "link.bazelrc"), testDir + "/./a.bazelrc"}, cycleErr.Cycle)
+	require.Equal(t, []string{fileA, filepath.Join(testDir, "link.bazelrc")}, cycleErr.ImportChain)
+}
+
+func TestDuplicateImportsAreWarnings(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "duplicates")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	newFile(t, testDir, "common.bazelrc", "build --common=true")
+	newFile(t, testDir, "ci.bazelrc", "import %workspace%/common.bazelrc")
+	require.NoError(t, os.Symlink(filepath.Join(testDir, "common.bazelrc"), filepath.Join(testDir, "link.bazelrc")))
+	require.NoError(t, os.Mkdir(filepath.Join(testDir, "subdir"), 0o755))
+	rootFile := newFile(t, testDir, "root.bazelrc", `import %workspace%/common.bazelrc
+import %workspace%/ci.bazelrc
+try-import %workspace%/subdir/../link.bazelrc`)
+
+	parser := NewBazelRcParser(testDir, &FlagData{})
+	contents, err := parser.ParsePath(rootFile)
+	require.NoError(t, err)
+	require.Equal(t, []string{"true", "true", "true"}, contents.entries["build"]["common"])
+
+	var warnings []*DuplicateImportError
+	for _, diagnostic := range contents.Diagnostics() {
+		require.Equal(t, SeverityWarning, diagnostic.Severity)
+		var duplicateErr *DuplicateImportError
+		require.ErrorAs(t, diagnostic.Err, &duplicateErr)
+		warnings = append(warnings, duplicateErr)
+	}
+	require.Len(t, warnings, 2)
+
+	require.Equal(t, filepath.Join(testDir, "ci.bazelrc"), warnings[0].File)
+	require.Equal(t, 1, warnings[0].Line)
+	require.Equal(t, rootFile, warnings[0].FirstImport.File)
+	require.Equal(t, 1, warnings[0].FirstImport.Line)
+
+	require.Equal(t, rootFile, warnings[1].File)
+	require.Equal(t, 3, warnings[1].Line)
+	require.Equal(t, testDir+"/subdir/../link.bazelrc", warnings[1].Path)
+}
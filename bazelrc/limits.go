@@ -0,0 +1,151 @@
+package bazelrc
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Limits bounds the resources used when parsing bazelrc files, e.g. when parsing files from untrusted repositories.
+// A zero value for any field means that quantity is unlimited.
+type Limits struct {
+	// MaxImportDepth is the maximum number of nested imports below the top-level file.
+	MaxImportDepth int
+	// MaxFiles is the maximum number of files which will be read, including the top-level file.
+	MaxFiles int
+	// MaxTotalBytes is the maximum number of bytes which will be read, summed across all files.
+	MaxTotalBytes int64
+	// MaxLineLength is the maximum length of a single line, in bytes.
+	MaxLineLength int
+}
+
+// DefaultSandboxLimits are the limits applied by WithSandbox.
+// They are far larger than any legitimate bazelrc should need.
+var DefaultSandboxLimits = Limits{
+	MaxImportDepth: 16,
+	MaxFiles:       256,
+	MaxTotalBytes:  16 << 20,
+	MaxLineLength:  64 << 10,
+}
+
+// ImportPolicy restricts which files may be imported, e.g. when parsing files from untrusted repositories.
+// It doesn't apply to the top-level file being parsed.
+// Violations are reported as errors even for `try-import`s.
+type ImportPolicy struct {
+	// RestrictToWorkspace refuses imports of files outside the workspace directory.
+	// Paths are compared after symlinks are resolved, if the parser's ImportResolver implements FileIdentifier.
+	RestrictToWorkspace bool
+	// DisallowAbsolutePaths refuses imports written as absolute paths.
+	// Paths starting with `%workspace%` are still allowed.
+	DisallowAbsolutePaths bool
+}
+
+// WithLimits makes the parser refuse to read more than limits allow.
+// Exceeding a limit is reported as a LimitExceededError.
+func WithLimits(limits Limits) ParserOption {
+	return func(p *BazelRcParser) {
+		p.limits = limits
+	}
+}
+
+// WithImportPolicy makes the parser refuse imports which policy doesn't allow.
+// Refused imports are reported as an ImportPolicyError.
+func WithImportPolicy(policy ImportPolicy) ParserOption {
+	return func(p *BazelRcParser) {
+		p.importPolicy = policy
+	}
+}
+
+// WithSandbox configures the parser for parsing bazelrc files from untrusted repositories:
+// DefaultSandboxLimits are applied, and only relative or `%workspace%` imports of files inside the workspace are allowed.
+func WithSandbox() ParserOption {
+	return func(p *BazelRcParser) {
+		WithLimits(DefaultSandboxLimits)(p)
+		WithImportPolicy(ImportPolicy{RestrictToWorkspace: true, DisallowAbsolutePaths: true})(p)
+	}
+}
+
+// LimitExceededError is returned when parsing would exceed one of the parser's Limits.
+type LimitExceededError struct {
+	Location
+	// Limit is the name of the field of Limits which would be exceeded, e.g. "MaxFiles".
+	Limit string
+	// Max is the configured value of the limit.
+	Max int64
+}
+
+func (e *LimitExceededError) Error() string {
+	return e.describeSelf(fmt.Sprintf("exceeded limit %s of %d", e.Limit, e.Max))
+}
+
+// ImportPolicyError is returned when an import isn't allowed by the parser's ImportPolicy.
+type ImportPolicyError struct {
+	// Location is the import statement.
+	Location
+	// Path is the path of the import, as written.
+	Path string
+	// Reason describes which policy the import violates.
+	Reason string
+}
+
+func (e *ImportPolicyError) Error() string {
+	return e.describeSelf(fmt.Sprintf("import of %s is not allowed: %s", e.Path, e.Reason))
+}
+
+// checkImportPolicy returns an ImportPolicyError if the import of importPath (which resolved to the file with identity identity) isn't allowed.
+func (p *BazelRcParser) checkImportPolicy(importPath string, identity FileIdentity, location Location) error {
+	if p.importPolicy.DisallowAbsolutePaths && !strings.HasPrefix(importPath, "%workspace%") && (filepath.IsAbs(importPath) || strings.HasPrefix(importPath, "/")) {
+		return &ImportPolicyError{Location: location, Path: importPath, Reason: "absolute import paths are disallowed"}
+	}
+	if p.importPolicy.RestrictToWorkspace {
+		workspace, err := p.resolver().Resolve("%workspace%", "")
+		if err != nil {
+			return &ImportPolicyError{Location: location, Path: importPath, Reason: fmt.Sprintf("unable to resolve workspace directory: %v", err)}
+		}
+		workspaceIdentity := identifyFile(p.resolver(), workspace)
+		if !isWithinDirectory(identity.CanonicalPath, workspaceIdentity.CanonicalPath) {
+			return &ImportPolicyError{Location: location, Path: importPath, Reason: fmt.Sprintf("%s is outside the workspace %s", identity.CanonicalPath, workspaceIdentity.CanonicalPath)}
+		}
+	}
+	return nil
+}
+
+// checkOpenedImport returns an ImportPolicyError if file, opened from resolvedPath, isn't the file whose identity was checked by checkImportPolicy,
+// e.g. because a symlink was replaced between checking and opening the import.
+// If the file didn't exist when it was checked, the policy is checked against the file which now exists.
+// Nothing can be checked if the parser's ImportResolver doesn't identify files by device and inode numbers, or if file doesn't support Stat.
+func (p *BazelRcParser) checkOpenedImport(importPath string, resolvedPath string, identity FileIdentity, file io.Reader, location Location) error {
+	if !p.importPolicy.RestrictToWorkspace {
+		return nil
+	}
+	if identity.fileInfo == nil {
+		identity = identifyFile(p.resolver(), resolvedPath)
+		if err := p.checkImportPolicy(importPath, identity, location); err != nil {
+			return err
+		}
+		if identity.fileInfo == nil {
+			return nil
+		}
+	}
+	statter, ok := file.(interface{ Stat() (fs.FileInfo, error) })
+	if !ok {
+		return nil
+	}
+	if fileInfo, err := statter.Stat(); err != nil || !os.SameFile(fileInfo, identity.fileInfo) {
+		return &ImportPolicyError{Location: location, Path: importPath, Reason: fmt.Sprintf("the file opened isn't %s, which was checked", identity.CanonicalPath)}
+	}
+	return nil
+}
+
+// isWithinDirectory returns whether path is directory, or is inside it.
+// Both paths must be clean, and either both absolute or both relative.
+func isWithinDirectory(path string, directory string) bool {
+	relative, err := filepath.Rel(directory, path)
+	if err != nil {
+		return false
+	}
+	return relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,220 @@
+package bazelrc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits(t *testing.T) {
+	fsys := fstest.MapFS{
+		"chain0.bazelrc": &fstest.MapFile{Data: []byte("import chain1.bazelrc")},
+		"chain1.bazelrc": &fstest.MapFile{Data: []byte("import chain2.bazelrc")},
+		"chain2.bazelrc": &fstest.MapFile{Data: []byte("import chain3.bazelrc")},
+		"chain3.bazelrc": &fstest.MapFile{Data: []byte("build --deep=true")},
+		"wide.bazelrc":   &fstest.MapFile{Data: []byte("import a.bazelrc\nimport b.bazelrc\nimport c.bazelrc")},
+		"a.bazelrc":      &fstest.MapFile{Data: []byte("build --a=true")},
+		"b.bazelrc":      &fstest.MapFile{Data: []byte("build --b=true")},
+		"c.bazelrc":      &fstest.MapFile{Data: []byte("build --c=true")},
+		"long.bazelrc":   &fstest.MapFile{Data: []byte("build --short=true\nbuild --" + strings.Repeat("x", 100) + "=true")},
+	}
+
+	for name, tc := range map[string]struct {
+		limits    Limits
+		path      string
+		wantLimit string
+		wantFile  string
+		wantLine  int
+	}{
+		"import depth within limit": {
+			limits: Limits{MaxImportDepth: 3},
+			path:   "chain0.bazelrc",
+		},
+		"import depth exceeded": {
+			limits:    Limits{MaxImportDepth: 2},
+			path:      "chain0.bazelrc",
+			wantLimit: "MaxImportDepth",
+			wantFile:  "chain2.bazelrc",
+			wantLine:  1,
+		},
+		"file count within limit": {
+			limits: Limits{MaxFiles: 4},
+			path:   "wide.bazelrc",
+		},
+		"file count exceeded": {
+			limits:    Limits{MaxFiles: 3},
+			path:      "wide.bazelrc",
+			wantLimit: "MaxFiles",
+			wantFile:  "wide.bazelrc",
+			wantLine:  3,
+		},
+		"total bytes within limit": {
+			limits: Limits{MaxTotalBytes: 50 + 3*14},
+			path:   "wide.bazelrc",
+		},
+		"total bytes exceeded": {
+			limits:    Limits{MaxTotalBytes: 50 + 3*14 - 1},
+			path:      "wide.bazelrc",
+			wantLimit: "MaxTotalBytes",
+			wantFile:  "c.bazelrc",
+		},
+		"line length within limit": {
+			limits: Limits{MaxLineLength: 113},
+			path:   "long.bazelrc",
+		},
+		"line length exceeded": {
+			limits:    Limits{MaxLineLength: 112},
+			path:      "long.bazelrc",
+			wantLimit: "MaxLineLength",
+			wantFile:  "long.bazelrc",
+			wantLine:  2,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			parser := NewBazelRcParser(".", &FlagData{}, WithFS(fsys), WithLimits(tc.limits))
+			_, err := parser.ParsePath(tc.path)
+			if tc.wantLimit == "" {
+				require.NoError(t, err)
+				return
+			}
+			var limitErr *LimitExceededError
+			require.ErrorAs(t, err, &limitErr)
+			require.Equal(t, tc.wantLimit, limitErr.Limit)
+			require.Equal(t, tc.wantFile, limitErr.File)
+			require.Equal(t, tc.wantLine, limitErr.Line)
+		})
+	}
+}
+
+func TestImportPolicy(t *testing.T) {
+	parentDir, err := os.MkdirTemp("", "policy")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentDir)
+
+	workspace := filepath.Join(parentDir, "workspace")
+	require.NoError(t, os.Mkdir(workspace, 0o755))
+	outside := newFile(t, parentDir, "outside.bazelrc", "build --outside=true")
+	newFile(t, workspace, "inside.bazelrc", "build --inside=true")
+	require.NoError(t, os.Symlink(outside, filepath.Join(workspace, "escape.bazelrc")))
+
+	for name, tc := range map[string]struct {
+		policy     ImportPolicy
+		importLine string
+		wantError  string
+	}{
+		"workspace import allowed": {
+			policy:     ImportPolicy{RestrictToWorkspace: true, DisallowAbsolutePaths: true},
+			importLine: "import %workspace%/inside.bazelrc",
+		},
+		"absolute import inside workspace allowed without DisallowAbsolutePaths": {
+			policy:     ImportPolicy{RestrictToWorkspace: true},
+			importLine: fmt.Sprintf("import %s/inside.bazelrc", workspace),
+		},
+		"absolute import disallowed": {
+			policy:     ImportPolicy{DisallowAbsolutePaths: true},
+			importLine: fmt.Sprintf("import %s/inside.bazelrc", workspace),
+			wantError:  "absolute import paths are disallowed",
+		},
+		"import outside workspace": {
+			policy:     ImportPolicy{RestrictToWorkspace: true},
+			importLine: "import %workspace%/../outside.bazelrc",
+			wantError:  "is outside the workspace",
+		},
+		"try-import outside workspace": {
+			policy:     ImportPolicy{RestrictToWorkspace: true},
+			importLine: "try-import %workspace%/../outside.bazelrc",
+			wantError:  "is outside the workspace",
+		},
+		"symlink escaping workspace": {
+			policy:     ImportPolicy{RestrictToWorkspace: true},
+			importLine: "import %workspace%/escape.bazelrc",
+			wantError:  "is outside the workspace",
+		},
+		"symlink allowed without policy": {
+			importLine: "import %workspace%/escape.bazelrc",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			parser := NewBazelRcParser(workspace, &FlagData{}, WithImportPolicy(tc.policy))
+			_, err := parser.Parsefile(strings.NewReader("build --foo=bar\n"+tc.importLine), filepath.Join(workspace, ".bazelrc"))
+			if tc.wantError == "" {
+				require.NoError(t, err)
+				return
+			}
+			var policyErr *ImportPolicyError
+			require.ErrorAs(t, err, &policyErr)
+			require.Equal(t, 2, policyErr.Line)
+			require.ErrorContains(t, err, tc.wantError)
+		})
+	}
+}
+
+// swappingImportResolver reads files from the local filesystem, but repoints the symlink at swap to target before opening a file,
+// as if it were replaced between the import policy being checked and the file being opened.
+type swappingImportResolver struct {
+	*osImportResolver
+	swap   string
+	target string
+}
+
+func (r *swappingImportResolver) Open(resolvedPath string) (io.ReadCloser, error) {
+	if err := os.Remove(r.swap); err != nil {
+		return nil, err
+	}
+	if err := os.Symlink(r.target, r.swap); err != nil {
+		return nil, err
+	}
+	return r.osImportResolver.Open(resolvedPath)
+}
+
+func TestImportPolicyChecksOpenedFile(t *testing.T) {
+	parentDir, err := os.MkdirTemp("", "policy")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentDir)
+
+	workspace := filepath.Join(parentDir, "workspace")
+	require.NoError(t, os.Mkdir(workspace, 0o755))
+	outside := newFile(t, parentDir, "outside.bazelrc", "build --outside=true")
+	inside := newFile(t, workspace, "inside.bazelrc", "build --inside=true")
+	swap := filepath.Join(workspace, "swap.bazelrc")
+	require.NoError(t, os.Symlink(inside, swap))
+
+	resolver := &swappingImportResolver{osImportResolver: &osImportResolver{workspaceDirectory: workspace}, swap: swap, target: outside}
+	parser := NewBazelRcParser(workspace, &FlagData{}, WithImportResolver(resolver), WithImportPolicy(ImportPolicy{RestrictToWorkspace: true}))
+	_, err = parser.Parsefile(strings.NewReader("build --foo=bar\nimport %workspace%/swap.bazelrc"), filepath.Join(workspace, ".bazelrc"))
+	var policyErr *ImportPolicyError
+	require.ErrorAs(t, err, &policyErr)
+	require.Equal(t, 2, policyErr.Line)
+	require.ErrorContains(t, err, "which was checked")
+}
+
+func TestSandboxCollectsViolations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"repo/.bazelrc": &fstest.MapFile{Data: []byte(`build --ok=true
+try-import /etc/passwd
+import %workspace%/../other/.bazelrc
+import %workspace%/tools/ci.bazelrc`)},
+		"repo/tools/ci.bazelrc": &fstest.MapFile{Data: []byte("build:ci --jobs=10")},
+		"other/.bazelrc":        &fstest.MapFile{Data: []byte("build --other=true")},
+	}
+	parser := NewBazelRcParser("repo", &FlagData{}, WithFS(fsys), WithSandbox())
+	file, err := fsys.Open("repo/.bazelrc")
+	require.NoError(t, err)
+	defer file.Close()
+	contents, diagnostics := parser.ParsefileCollectingDiagnostics(file, "repo/.bazelrc")
+	require.Equal(t, map[string]BazelFlagValues{
+		"build":    {"ok": []string{"true"}},
+		"build:ci": {"jobs": []string{"10"}},
+	}, contents.entries)
+	require.Len(t, diagnostics, 2)
+	for _, diagnostic := range diagnostics {
+		var policyErr *ImportPolicyError
+		require.ErrorAs(t, diagnostic.Err, &policyErr)
+	}
+}
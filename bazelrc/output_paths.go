@@ -0,0 +1,113 @@
+package bazelrc
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"path"
+	"strings"
+)
+
+// OutputPathsEnvironment describes the environment the Bazel client runs in, from which its output paths are derived.
+type OutputPathsEnvironment struct {
+	// WorkspaceDirectory is the absolute path of the workspace, as reported by getcwd (i.e. with symlinks resolved).
+	WorkspaceDirectory string
+	// WorkspaceName is the name of the workspace's directory within the execution root.
+	// If empty, "_main" is used, which is the name used with Bzlmod.
+	WorkspaceName string
+	// WorkingDirectory is the directory bazel was run from, against which relative `--output_base` and `--output_user_root` values are resolved.
+	// If empty, WorkspaceDirectory is used.
+	WorkingDirectory string
+	// UserName is the name of the current user, which is used if $USER isn't set.
+	UserName string
+	// HomeDirectory is the home directory of the current user, which is used if $HOME isn't set.
+	HomeDirectory string
+	// Env holds the client's environment variables.
+	Env map[string]string
+}
+
+// OutputPaths holds the locations Bazel writes its outputs to.
+type OutputPaths struct {
+	// OutputUserRoot is the directory beneath which output bases are created by default, e.g. `~/.cache/bazel/_bazel_$USER`.
+	OutputUserRoot string
+	// OutputBase is the directory holding all of the workspace's outputs, e.g. `~/.cache/bazel/_bazel_$USER/<MD5 of the workspace path>`.
+	OutputBase string
+	// ExecutionRoot is the directory actions are run in, which contains `bazel-out`.
+	ExecutionRoot string
+	// CommandLog is the file holding the output of the most recent command.
+	CommandLog string
+}
+
+// ComputeOutputPaths computes where Bazel writes its outputs, the way Bazel's client does on Linux, without invoking bazel.
+// Symlinks in `--output_base` aren't resolved, which Bazel does if the directory already exists.
+func ComputeOutputPaths(environment OutputPathsEnvironment, startupOptions *StartupOptions) (*OutputPaths, error) {
+	if environment.WorkspaceDirectory == "" {
+		return nil, errors.New("failed to compute output paths: no workspace directory")
+	}
+	workingDirectory := environment.WorkingDirectory
+	if workingDirectory == "" {
+		workingDirectory = environment.WorkspaceDirectory
+	}
+	if startupOptions == nil {
+		startupOptions = &StartupOptions{}
+	}
+
+	outputUserRoot := startupOptions.OutputUserRoot()
+	if outputUserRoot != "" {
+		outputUserRoot = absolutePathFromFlag(outputUserRoot, workingDirectory)
+	} else {
+		userName := environment.Env["USER"]
+		if userName == "" {
+			userName = environment.UserName
+		}
+		if userName == "" {
+			return nil, errors.New("failed to compute output paths: unable to determine the current user")
+		}
+		outputUserRoot = path.Join(environment.outputRoot(), "_bazel_"+userName)
+	}
+
+	outputBase := startupOptions.OutputBase()
+	if outputBase != "" {
+		outputBase = absolutePathFromFlag(outputBase, workingDirectory)
+	} else {
+		digest := md5.Sum([]byte(environment.WorkspaceDirectory))
+		outputBase = path.Join(outputUserRoot, hex.EncodeToString(digest[:]))
+	}
+
+	workspaceName := environment.WorkspaceName
+	if workspaceName == "" {
+		workspaceName = "_main"
+	}
+	return &OutputPaths{
+		OutputUserRoot: outputUserRoot,
+		OutputBase:     outputBase,
+		ExecutionRoot:  path.Join(outputBase, "execroot", workspaceName),
+		CommandLog:     path.Join(outputBase, "command.log"),
+	}, nil
+}
+
+// outputRoot returns the directory beneath which output user roots are created: $TEST_TMPDIR when running under `bazel test`, otherwise the user's cache directory.
+func (e OutputPathsEnvironment) outputRoot() string {
+	if testTmpdir := e.Env["TEST_TMPDIR"]; testTmpdir != "" {
+		return path.Clean(testTmpdir)
+	}
+	if cacheHome := e.Env["XDG_CACHE_HOME"]; cacheHome != "" {
+		return path.Join(cacheHome, "bazel")
+	}
+	home := e.Env["HOME"]
+	if home == "" {
+		home = e.HomeDirectory
+	}
+	if home == "" {
+		return "/tmp"
+	}
+	return path.Join(home, ".cache", "bazel")
+}
+
+// absolutePathFromFlag resolves the value of a path-valued startup option against workingDirectory.
+func absolutePathFromFlag(value string, workingDirectory string) string {
+	if strings.HasPrefix(value, "/") {
+		return path.Clean(value)
+	}
+	return path.Join(workingDirectory, value)
+}
@@ -0,0 +1,123 @@
+package bazelrc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeOutputPaths(t *testing.T) {
+	const workspaceHash = "a98ab360bd19925198be45fc0d3911af"
+	for name, tc := range map[string]struct {
+		environment OutputPathsEnvironment
+		startup     []Option
+		want        *OutputPaths
+		wantErr     string
+	}{
+		"defaults": {
+			environment: OutputPathsEnvironment{
+				WorkspaceDirectory: "/home/alice/src/project",
+				Env:                map[string]string{"USER": "alice", "HOME": "/home/alice"},
+			},
+			want: &OutputPaths{
+				OutputUserRoot: "/home/alice/.cache/bazel/_bazel_alice",
+				OutputBase:     "/home/alice/.cache/bazel/_bazel_alice/" + workspaceHash,
+				ExecutionRoot:  "/home/alice/.cache/bazel/_bazel_alice/" + workspaceHash + "/execroot/_main",
+				CommandLog:     "/home/alice/.cache/bazel/_bazel_alice/" + workspaceHash + "/command.log",
+			},
+		},
+		"user and home from the passwd database": {
+			environment: OutputPathsEnvironment{
+				WorkspaceDirectory: "/home/alice/src/project",
+				WorkspaceName:      "project",
+				UserName:           "bob",
+				HomeDirectory:      "/home/bob",
+			},
+			want: &OutputPaths{
+				OutputUserRoot: "/home/bob/.cache/bazel/_bazel_bob",
+				OutputBase:     "/home/bob/.cache/bazel/_bazel_bob/" + workspaceHash,
+				ExecutionRoot:  "/home/bob/.cache/bazel/_bazel_bob/" + workspaceHash + "/execroot/project",
+				CommandLog:     "/home/bob/.cache/bazel/_bazel_bob/" + workspaceHash + "/command.log",
+			},
+		},
+		"XDG_CACHE_HOME": {
+			environment: OutputPathsEnvironment{
+				WorkspaceDirectory: "/home/alice/src/project",
+				Env:                map[string]string{"USER": "alice", "HOME": "/home/alice", "XDG_CACHE_HOME": "/cache/"},
+			},
+			want: &OutputPaths{
+				OutputUserRoot: "/cache/bazel/_bazel_alice",
+				OutputBase:     "/cache/bazel/_bazel_alice/" + workspaceHash,
+				ExecutionRoot:  "/cache/bazel/_bazel_alice/" + workspaceHash + "/execroot/_main",
+				CommandLog:     "/cache/bazel/_bazel_alice/" + workspaceHash + "/command.log",
+			},
+		},
+		"TEST_TMPDIR": {
+			environment: OutputPathsEnvironment{
+				WorkspaceDirectory: "/home/alice/src/project",
+				Env:                map[string]string{"USER": "alice", "HOME": "/home/alice", "XDG_CACHE_HOME": "/cache", "TEST_TMPDIR": "/tmp/test"},
+			},
+			want: &OutputPaths{
+				OutputUserRoot: "/tmp/test/_bazel_alice",
+				OutputBase:     "/tmp/test/_bazel_alice/" + workspaceHash,
+				ExecutionRoot:  "/tmp/test/_bazel_alice/" + workspaceHash + "/execroot/_main",
+				CommandLog:     "/tmp/test/_bazel_alice/" + workspaceHash + "/command.log",
+			},
+		},
+		"no home directory": {
+			environment: OutputPathsEnvironment{
+				WorkspaceDirectory: "/home/alice/src/project",
+				UserName:           "alice",
+			},
+			want: &OutputPaths{
+				OutputUserRoot: "/tmp/_bazel_alice",
+				OutputBase:     "/tmp/_bazel_alice/" + workspaceHash,
+				ExecutionRoot:  "/tmp/_bazel_alice/" + workspaceHash + "/execroot/_main",
+				CommandLog:     "/tmp/_bazel_alice/" + workspaceHash + "/command.log",
+			},
+		},
+		"output user root": {
+			environment: OutputPathsEnvironment{
+				WorkspaceDirectory: "/home/alice/src/project",
+				WorkingDirectory:   "/home/alice/src/project/pkg",
+			},
+			startup: []Option{
+				{Name: "output_user_root", Value: "/ignored"},
+				{Name: "output_user_root", Value: "../root"},
+			},
+			want: &OutputPaths{
+				OutputUserRoot: "/home/alice/src/project/root",
+				OutputBase:     "/home/alice/src/project/root/" + workspaceHash,
+				ExecutionRoot:  "/home/alice/src/project/root/" + workspaceHash + "/execroot/_main",
+				CommandLog:     "/home/alice/src/project/root/" + workspaceHash + "/command.log",
+			},
+		},
+		"output base": {
+			environment: OutputPathsEnvironment{
+				WorkspaceDirectory: "/home/alice/src/project",
+				Env:                map[string]string{"USER": "alice", "HOME": "/home/alice"},
+			},
+			startup: []Option{{Name: "output_base", Value: "/tmp/out/"}},
+			want: &OutputPaths{
+				OutputUserRoot: "/home/alice/.cache/bazel/_bazel_alice",
+				OutputBase:     "/tmp/out",
+				ExecutionRoot:  "/tmp/out/execroot/_main",
+				CommandLog:     "/tmp/out/command.log",
+			},
+		},
+		"unknown user": {
+			environment: OutputPathsEnvironment{WorkspaceDirectory: "/home/alice/src/project"},
+			wantErr:     "failed to compute output paths: unable to determine the current user",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			paths, err := ComputeOutputPaths(tc.environment, &StartupOptions{Options: tc.startup})
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, paths)
+		})
+	}
+}
@@ -1,44 +1,168 @@
 package bazelrc
 
 import (
+	"errors"
 	"fmt"
 	"io"
-	"os"
+	"io/fs"
 	"strings"
 
-	"github.com/google/shlex"
 	"golang.org/x/exp/slices"
 )
 
 // NewBazelRcParser makes a parser of bazelrc files.
-func NewBazelRcParser(workspaceDirectory string, knownFlagData *FlagData) *BazelRcParser {
-	return &BazelRcParser{
+// By default imports are read from the local filesystem, with `%workspace%` replaced by workspaceDirectory.
+func NewBazelRcParser(workspaceDirectory string, knownFlagData *FlagData, options ...ParserOption) *BazelRcParser {
+	p := &BazelRcParser{
 		workspaceDirectory: workspaceDirectory,
 		knownFlagData:      knownFlagData,
 	}
+	for _, option := range options {
+		option(p)
+	}
+	return p
 }
 
 type BazelRcParser struct {
 	workspaceDirectory string
 	knownFlagData      *FlagData
+	// importResolver is used to find and open imported files. If nil, files are read from the local filesystem.
+	importResolver ImportResolver
+	// limits bounds the resources used while parsing.
+	limits Limits
+	// importPolicy restricts which files may be imported.
+	importPolicy ImportPolicy
+}
+
+// ParserOption configures optional behaviour of a BazelRcParser.
+type ParserOption func(*BazelRcParser)
+
+// WithImportResolver makes the parser find and open files using resolver, rather than from the local filesystem.
+func WithImportResolver(resolver ImportResolver) ParserOption {
+	return func(p *BazelRcParser) {
+		p.importResolver = resolver
+	}
+}
+
+// WithFS makes the parser read files from fsys, rather than from the local filesystem.
+// The parser's workspace directory is interpreted as a path within fsys; see NewFSImportResolver for how paths are resolved.
+func WithFS(fsys fs.FS) ParserOption {
+	return func(p *BazelRcParser) {
+		p.importResolver = NewFSImportResolver(fsys, p.workspaceDirectory)
+	}
+}
+
+func (p *BazelRcParser) resolver() ImportResolver {
+	if p.importResolver == nil {
+		return NewOSImportResolver(p.workspaceDirectory)
+	}
+	return p.importResolver
 }
 
 // Parsefile parses a bazelrc file.
 func (p *BazelRcParser) Parsefile(file io.Reader, filePath string) (*BazelrcContents, error) {
-	contents := newBazelrcContents()
-	return contents, p.parseFileInternal(contents, file, []string{filePath})
+	state := p.newParseState(filePath, false)
+	return state.contents, p.parseFileInternal(state, file, []string{filePath})
+}
+
+// ParsePath opens and parses the bazelrc file at filePath, using the parser's ImportResolver.
+// filePath may contain `%workspace%`, just like an import statement.
+func (p *BazelRcParser) ParsePath(filePath string) (*BazelrcContents, error) {
+	resolver := p.resolver()
+	resolvedPath, err := resolver.Resolve(filePath, "")
+	if err != nil {
+		return nil, &ParseError{Location: newLocation([]string{filePath}, -1), Err: fmt.Errorf("unable to resolve path: %w", err)}
+	}
+	file, err := resolver.Open(resolvedPath)
+	if err != nil {
+		return nil, &ParseError{Location: newLocation([]string{resolvedPath}, -1), Err: fmt.Errorf("unable to open file: %w", err)}
+	}
+	defer file.Close()
+	return p.Parsefile(file, resolvedPath)
+}
+
+// ParsefileCollectingDiagnostics parses a bazelrc file, without stopping at the first problem found.
+// Each problem is recorded as a Diagnostic, and the line it was found on is skipped (including any continuation lines), with parsing continuing from the following line (and through any imports).
+// The returned BazelrcContents contains everything which could be parsed, and is never nil.
+func (p *BazelRcParser) ParsefileCollectingDiagnostics(file io.Reader, filePath string) (*BazelrcContents, []Diagnostic) {
+	state := p.newParseState(filePath, true)
+	// When collecting diagnostics, all errors are recorded rather than returned.
+	_ = p.parseFileInternal(state, file, []string{filePath})
+	return state.contents, state.contents.diagnostics
+}
+
+// parseState holds the state of a single call to Parsefile, which may span several imported files.
+type parseState struct {
+	contents *BazelrcContents
+	// collectDiagnostics causes errors to be recorded in contents, rather than aborting parsing.
+	collectDiagnostics bool
+	// importStack holds the identity of each file in the import call stack, used to detect import cycles.
+	importStack []FileIdentity
+	// importedFiles records every file which has been imported, and where, used to detect duplicate imports.
+	importedFiles []importedFile
+	// flagAliases holds the aliases from FlagData, which apply to every line.
+	flagAliases map[string]string
+	// declaredFlagAliases holds the aliases declared so far by each command's lines, which apply to later lines for commands which inherit from it.
+	declaredFlagAliases map[string]map[string]string
+	// filesRead and bytesRead count what has been read so far, to enforce limits.
+	filesRead int
+	bytesRead int64
+}
+
+func (p *BazelRcParser) newParseState(filePath string, collectDiagnostics bool) *parseState {
+	return &parseState{
+		contents:            newBazelrcContents(),
+		collectDiagnostics:  collectDiagnostics,
+		importStack:         []FileIdentity{identifyFile(p.resolver(), filePath)},
+		flagAliases:         copyFlagAliases(p.knownFlagData),
+		declaredFlagAliases: make(map[string]map[string]string),
+	}
+}
+
+type importedFile struct {
+	identity FileIdentity
+	// location is the import statement which first imported the file.
+	location Location
+}
+
+// warn records a problem which doesn't prevent parsing, even when diagnostics aren't otherwise being collected.
+func (s *parseState) warn(err error) {
+	s.contents.diagnostics = append(s.contents.diagnostics, newDiagnostic(SeverityWarning, err))
+}
+
+// report handles an error found while parsing.
+// If diagnostics are being collected, the error is recorded and nil is returned, and the caller should skip past the problem.
+// Otherwise the error is returned, and the caller should stop parsing.
+func (s *parseState) report(err error) error {
+	if !s.collectDiagnostics {
+		return err
+	}
+	s.contents.diagnostics = append(s.contents.diagnostics, newDiagnostic(SeverityError, err))
+	return nil
 }
 
 // importCallStack should always contain at least one element. This func is called by Parsefile which passes top level path, any recursive calls append to the importCallStack slice.
-func (p *BazelRcParser) parseFileInternal(out *BazelrcContents, file io.Reader, importCallStack []string) error {
+func (p *BazelRcParser) parseFileInternal(state *parseState, file io.Reader, importCallStack []string) error {
+	state.filesRead++
+	if p.limits.MaxTotalBytes > 0 {
+		// Read one byte more than allowed, so that we can tell whether the limit was exceeded.
+		file = io.LimitReader(file, p.limits.MaxTotalBytes-state.bytesRead+1)
+	}
 	byteValue, err := io.ReadAll(file)
 	if err != nil {
-		return makeError(importCallStack, -1, fmt.Errorf("failed to read file: %w", err), false)
+		return state.report(&ParseError{Location: newLocation(importCallStack, -1), Err: fmt.Errorf("failed to read file: %w", err)})
 	}
-
-	var flagNameExpectingValueWithLeadingDashes *string
+	state.bytesRead += int64(len(byteValue))
+	if p.limits.MaxTotalBytes > 0 && state.bytesRead > p.limits.MaxTotalBytes {
+		return state.report(&LimitExceededError{Location: newLocation(importCallStack, -1), Limit: "MaxTotalBytes", Max: p.limits.MaxTotalBytes})
+	}
+	state.contents.recordConsultedFile(importCallStack[len(importCallStack)-1], true, byteValue)
 
 	lines := strings.Split(string(byteValue), "\n")
+	lineStartOffsets := make([]int, len(lines))
+	for i := 1; i < len(lines); i++ {
+		lineStartOffsets[i] = lineStartOffsets[i-1] + len(lines[i-1]) + 1
+	}
 	for zeroBaseLineNumber := 0; zeroBaseLineNumber < len(lines); zeroBaseLineNumber++ {
 		line := lines[zeroBaseLineNumber]
 
@@ -46,52 +170,103 @@ func (p *BazelRcParser) parseFileInternal(out *BazelrcContents, file io.Reader,
 		if strings.HasPrefix(line, "#") || line == "" {
 			continue
 		}
-		tokens, err := tokenizeLine(line, importCallStack, zeroBaseLineNumber)
+		tokens, err := p.tokenizeLine(lines, lineStartOffsets, importCallStack, zeroBaseLineNumber)
 		if err != nil {
-			return err
+			if err := state.report(err); err != nil {
+				return err
+			}
+			continue
 		}
 		if len(tokens) == 0 {
 			continue
 		}
 
-		commandName := tokens[0]
+		commandName := tokens[0].Value
 		commandArgumentsCount := len(tokens[1:])
 
 		if commandName == "import" || commandName == "try-import" {
 			if commandArgumentsCount != 1 {
-				return makeError(importCallStack, zeroBaseLineNumber, fmt.Errorf("expected exactly 1 argument after %v, but got %v", commandName, commandArgumentsCount), false)
+				if err := state.report(&ParseError{Location: newSpanLocation(importCallStack, joinSpans(tokens[0].Span, tokens[len(tokens)-1].Span)), Err: fmt.Errorf("expected exactly 1 argument after %v, but got %v", commandName, commandArgumentsCount)}); err != nil {
+					return err
+				}
+				continue
 			}
 
-			pathFinal := strings.ReplaceAll(tokens[1], "%workspace%", p.workspaceDirectory)
-			importCallStackCopy := make([]string, len(importCallStack))
-			copy(importCallStackCopy, importCallStack)
+			location := newSpanLocation(importCallStack, tokens[1].Span)
+			pathFinal, err := p.resolver().Resolve(tokens[1].Value, importCallStack[len(importCallStack)-1])
+			if err != nil {
+				if err := state.report(&ParseError{Location: location, Err: fmt.Errorf("unable to resolve import %q: %w", tokens[1].Value, err)}); err != nil {
+					return err
+				}
+				continue
+			}
 
-			importCycleErrorMessage := "bazelrc file contains import cycle"
+			// Files are compared by identity rather than path, as the same file may be reachable by several paths (e.g. via symlinks).
+			identity := identifyFile(p.resolver(), pathFinal)
+			if err := p.checkImportPolicy(tokens[1].Value, identity, location); err != nil {
+				if err := state.report(err); err != nil {
+					return err
+				}
+				continue
+			}
+			if p.limits.MaxImportDepth > 0 && len(importCallStack) > p.limits.MaxImportDepth {
+				if err := state.report(&LimitExceededError{Location: location, Limit: "MaxImportDepth", Max: int64(p.limits.MaxImportDepth)}); err != nil {
+					return err
+				}
+				continue
+			}
+			if cycleStart := slices.IndexFunc(state.importStack, identity.SameFile); cycleStart != -1 {
+				if err := state.report(&ImportCycleError{
+					Location: location,
+					Cycle:    appendImport(importCallStack[cycleStart:], pathFinal),
+				}); err != nil {
+					return err
+				}
+				continue
+			}
+			importCallStackCopy := appendImport(importCallStack, pathFinal)
 
-			if slices.Contains(importCallStackCopy, pathFinal) {
-				importCallStackCopy = append(importCallStackCopy, pathFinal)
-				return makeError(importCallStackCopy, zeroBaseLineNumber, fmt.Errorf(importCycleErrorMessage), false)
-			} else {
-				importCallStackCopy = append(importCallStackCopy, pathFinal)
+			if p.limits.MaxFiles > 0 && state.filesRead >= p.limits.MaxFiles {
+				if err := state.report(&LimitExceededError{Location: location, Limit: "MaxFiles", Max: int64(p.limits.MaxFiles)}); err != nil {
+					return err
+				}
+				continue
 			}
 
-			file, err := os.Open(pathFinal)
+			file, err := p.resolver().Open(pathFinal)
 			if err != nil {
+				state.contents.recordConsultedFile(pathFinal, false, nil)
 				if commandName == "import" {
-					return makeError(importCallStackCopy, zeroBaseLineNumber, fmt.Errorf("unable to open file: %w", err), false)
-				} else {
-					continue
+					if err := state.report(&MissingImportError{Location: location, Path: pathFinal, Err: err}); err != nil {
+						return err
+					}
 				}
+				continue
+			}
+			if err := p.checkOpenedImport(tokens[1].Value, pathFinal, identity, file, location); err != nil {
+				file.Close()
+				if err := state.report(err); err != nil {
+					return err
+				}
+				continue
+			}
+			if previousImport := slices.IndexFunc(state.importedFiles, func(f importedFile) bool { return f.identity.SameFile(identity) }); previousImport != -1 {
+				state.warn(&DuplicateImportError{Location: location, Path: pathFinal, FirstImport: state.importedFiles[previousImport].location})
+			} else {
+				state.importedFiles = append(state.importedFiles, importedFile{identity: identity, location: location})
 			}
-			defer file.Close()
 
-			err = p.parseFileInternal(out, file, importCallStackCopy)
+			state.importStack = append(state.importStack, identity)
+			err = p.parseFileInternal(state, file, importCallStackCopy)
+			state.importStack = state.importStack[:len(state.importStack)-1]
+			file.Close()
 			if err != nil {
 				// Avoid repeating the same error prefix repeatedly per file in the import cycle
-				if strings.Contains(err.Error(), importCycleErrorMessage) {
+				var importCycleError *ImportCycleError
+				if errors.As(err, &importCycleError) {
 					return err
 				}
-				return makeError(importCallStackCopy, zeroBaseLineNumber, fmt.Errorf("unable to parse import file due to error: %w", err), true)
+				return wrapImportError(importCallStackCopy, err)
 			}
 			continue
 		}
@@ -102,11 +277,6 @@ func (p *BazelRcParser) parseFileInternal(out *BazelrcContents, file io.Reader,
 			continue
 		}
 
-		_, alreadyContainsMap := out.entries[commandName]
-		if !alreadyContainsMap {
-			out.entries[commandName] = make(map[string][]string)
-		}
-
 		// Accumulate and discard any targets found.
 		// We may want to do something with them in the future, but for now, none of our use-cases need that.
 		//
@@ -117,97 +287,207 @@ func (p *BazelRcParser) parseFileInternal(out *BazelrcContents, file io.Reader,
 		// It's not generally encouraged to use bazelrc files like this, but it is supported, so we should support it.
 		var targets []string
 
-		if err := p.parseLineWithoutCommandPrefix(tokens[1:], lines, &zeroBaseLineNumber, out.entries[commandName], &targets, &flagNameExpectingValueWithLeadingDashes, importCallStack); err != nil {
-			return err
+		// Options are accumulated per line, so that a line containing an error contributes nothing.
+		var lineOptions []Option
+		var flagExpectingValue *pendingFlag
+		lineParser := p.parserForCommand(commandName)
+		firstLineNumber := zeroBaseLineNumber
+		err = lineParser.parseLineWithoutCommandPrefix(tokens[1:], lines, lineStartOffsets, &zeroBaseLineNumber, &lineOptions, &targets, &flagExpectingValue, state.flagAliasesForLine(commandName), importCallStack)
+		if err == nil && flagExpectingValue != nil {
+			err = &MissingFlagValueError{Location: newSpanLocation(importCallStack, flagExpectingValue.token.Span), Flag: stripLeadingDashes(flagExpectingValue.nameWithLeadingDashes)}
+		}
+		if err == nil {
+			err = lineParser.normalizeOptionValues(lineOptions, importCallStack)
+		}
+		if err != nil {
+			if err := state.report(err); err != nil {
+				return err
+			}
+			zeroBaseLineNumber = lastContinuationLine(lines, zeroBaseLineNumber)
+			continue
+		}
+
+		if _, alreadyContainsMap := state.contents.entries[commandName]; !alreadyContainsMap {
+			state.contents.entries[commandName] = make(map[string][]string)
+		}
+		for _, option := range lineOptions {
+			option.Command = commandName
+			option.CommandSpan = tokens[0].Span
+			state.contents.addOption(option)
+		}
+		state.declareFlagAliases(commandName, lineOptions)
+		state.contents.lines = append(state.contents.lines, rcLine{
+			command: commandName,
+			source:  importCallStack[len(importCallStack)-1],
+			args:    p.continuedLineTokens(tokens[1:], lines, lineStartOffsets, importCallStack, firstLineNumber, zeroBaseLineNumber),
+		})
+	}
+
+	return nil
+}
+
+// parserForCommand returns the parser to use for the options of a line for command.
+// `startup` lines are parsed using the startup option schema, if it's known.
+func (p *BazelRcParser) parserForCommand(command string) *BazelRcParser {
+	if command != "startup" || p.knownFlagData == nil || p.knownFlagData.StartupOptions == nil {
+		return p
+	}
+	startupParser := *p
+	startupParser.knownFlagData = p.knownFlagData.StartupOptions
+	return &startupParser
+}
+
+// continuedLineTokens returns the tokens of the logical line made up of lines[firstLineNumber] to lines[lastLineNumber], excluding the `\` tokens which continue it.
+// firstLineTokens are the tokens of the first line, and the rest are known to tokenize successfully, as they've already been parsed.
+func (p *BazelRcParser) continuedLineTokens(firstLineTokens []Token, lines []string, lineStartOffsets []int, importCallStack []string, firstLineNumber int, lastLineNumber int) []Token {
+	var continuedTokens []Token
+	lineTokens := firstLineTokens
+	for lineNumber := firstLineNumber; ; lineNumber++ {
+		if len(lineTokens) != 0 && lineTokens[len(lineTokens)-1].Value == "\\" {
+			lineTokens = lineTokens[:len(lineTokens)-1]
 		}
+		continuedTokens = append(continuedTokens, lineTokens...)
+		if lineNumber >= lastLineNumber || lineNumber+1 == len(lines) {
+			return continuedTokens
+		}
+		lineTokens, _ = p.tokenizeLine(lines, lineStartOffsets, importCallStack, lineNumber+1)
 	}
+}
 
-	return err
+// lastContinuationLine returns the index of the last physical line making up the logical line which lines[zeroBaseLineNumber] is part of.
+// Lines which can't be tokenized are assumed not to be continued.
+func lastContinuationLine(lines []string, zeroBaseLineNumber int) int {
+	for zeroBaseLineNumber+1 < len(lines) {
+		tokens, err := tokenize(lines[zeroBaseLineNumber], Position{})
+		if err != nil || len(tokens) == 0 || tokens[len(tokens)-1].Value != "\\" {
+			break
+		}
+		zeroBaseLineNumber++
+	}
+	return zeroBaseLineNumber
 }
 
-func tokenizeLine(line string, importCallStack []string, zeroBaseLineNumber int) ([]string, error) {
-	tokens, err := shlex.Split(line)
+// tokenizeLine splits lines[zeroBaseLineNumber] into tokens.
+// lineStartOffsets holds the byte offset within the file of the start of each line.
+func (p *BazelRcParser) tokenizeLine(lines []string, lineStartOffsets []int, importCallStack []string, zeroBaseLineNumber int) ([]Token, error) {
+	if p.limits.MaxLineLength > 0 && len(lines[zeroBaseLineNumber]) > p.limits.MaxLineLength {
+		return nil, &LimitExceededError{Location: newLocation(importCallStack, zeroBaseLineNumber), Limit: "MaxLineLength", Max: int64(p.limits.MaxLineLength)}
+	}
+	start := Position{
+		File:     importCallStack[len(importCallStack)-1],
+		Line:     zeroBaseLineNumber + 1,
+		Column:   1,
+		Offset:   lineStartOffsets[zeroBaseLineNumber],
+		ArgIndex: -1,
+	}
+	tokens, err := tokenize(lines[zeroBaseLineNumber], start)
 	if err != nil {
-		return nil, makeError(importCallStack, zeroBaseLineNumber, fmt.Errorf("unable to split line: %w", err), false)
+		var tokenizeErr *tokenizeError
+		if errors.As(err, &tokenizeErr) {
+			return nil, &TokenizeError{Location: newSpanLocation(importCallStack, tokenizeErr.span), Err: errors.New(tokenizeErr.message)}
+		}
+		return nil, &TokenizeError{Location: newLocation(importCallStack, zeroBaseLineNumber), Err: err}
 	}
 	return tokens, nil
 }
 
-func (p *BazelRcParser) parseLineWithoutCommandPrefix(tokens []string, lines []string, zeroBaseLineNumber *int, entriesForCommand map[string][]string, targetAccumulator *[]string, flagNameExpectingValueWithLeadingDashes **string, importCallStack []string) error {
+func (p *BazelRcParser) parseLineWithoutCommandPrefix(tokens []Token, lines []string, lineStartOffsets []int, zeroBaseLineNumber *int, options *[]Option, targetAccumulator *[]string, flagExpectingValue **pendingFlag, flagAliases map[string]string, importCallStack []string) error {
 	for i, token := range tokens {
-		parseNextLineAsContinuation, parseRestOfLineAsTargets, err := p.parseToken(token, i+1 == len(tokens), entriesForCommand, targetAccumulator, flagNameExpectingValueWithLeadingDashes, importCallStack, *zeroBaseLineNumber)
+		optionsBefore := len(*options)
+		parseNextLineAsContinuation, parseRestOfLineAsTargets, err := p.parseToken(token, i+1 == len(tokens), options, targetAccumulator, flagExpectingValue, flagAliases, importCallStack)
 		if err != nil {
 			return err
 		}
+		if err := recordFlagAliases((*options)[optionsBefore:], flagAliases, importCallStack); err != nil {
+			return err
+		}
 		if parseNextLineAsContinuation {
 			*zeroBaseLineNumber += 1
 			if *zeroBaseLineNumber == len(lines) {
 				return nil
 			}
-			tokens, err := tokenizeLine(lines[*zeroBaseLineNumber], importCallStack, *zeroBaseLineNumber)
+			tokens, err := p.tokenizeLine(lines, lineStartOffsets, importCallStack, *zeroBaseLineNumber)
 			if err != nil {
 				return err
 			}
-			if err := p.parseLineWithoutCommandPrefix(tokens, lines, zeroBaseLineNumber, entriesForCommand, targetAccumulator, flagNameExpectingValueWithLeadingDashes, importCallStack); err != nil {
+			if err := p.parseLineWithoutCommandPrefix(tokens, lines, lineStartOffsets, zeroBaseLineNumber, options, targetAccumulator, flagExpectingValue, flagAliases, importCallStack); err != nil {
 				return err
 			}
 		}
 		if parseRestOfLineAsTargets {
-			*targetAccumulator = append(*targetAccumulator, tokens[i+1:]...)
+			*targetAccumulator = append(*targetAccumulator, tokenValues(tokens[i+1:])...)
 			return nil
 		}
 	}
 	return nil
 }
 
-func (p *BazelRcParser) parseTokenizedArgsOnSingleLineAfterCommand(tokens []string, argAccumulator map[string][]string, targetAccumulator *[]string, flagNameExpectingValueWithLeadingDashes **string, importCallStack []string, zeroBaseLineNumber int) (bool, error) {
+func (p *BazelRcParser) parseTokenizedArgsOnSingleLineAfterCommand(tokens []Token, options *[]Option, targetAccumulator *[]string, flagExpectingValue **pendingFlag, flagAliases map[string]string, importCallStack []string) (bool, error) {
 	for i, token := range tokens {
-		parseNextLineAsContinuation, parseRestOfLineAsTargets, err := p.parseToken(token, i+1 == len(tokens), argAccumulator, targetAccumulator, flagNameExpectingValueWithLeadingDashes, importCallStack, zeroBaseLineNumber)
+		optionsBefore := len(*options)
+		parseNextLineAsContinuation, parseRestOfLineAsTargets, err := p.parseToken(token, i+1 == len(tokens), options, targetAccumulator, flagExpectingValue, flagAliases, importCallStack)
 		if err != nil {
 			return false, err
 		}
+		if err := recordFlagAliases((*options)[optionsBefore:], flagAliases, importCallStack); err != nil {
+			return false, err
+		}
 		if parseNextLineAsContinuation {
 			return true, nil
 		}
 		if parseRestOfLineAsTargets {
-			*targetAccumulator = append(*targetAccumulator, tokens[i+1:]...)
+			*targetAccumulator = append(*targetAccumulator, tokenValues(tokens[i+1:])...)
 			return false, nil
 		}
 	}
 	return false, nil
 }
 
+// pendingFlag is a flag which was seen without a value, whose value may be the following token.
+type pendingFlag struct {
+	// nameWithLeadingDashes is the full name of the flag, even if token is an abbreviation.
+	nameWithLeadingDashes string
+	token                 Token
+}
+
 // Return values:
 // * bool: Whether this token means the next line should be parsed as a continuation of this one.
 // * bool: Whether this token means that the rest of the line should be treated as targets and accumulated in targetAccumulator (which this function can't do, because it only sees one token at a time).
 // * error: Whether a fatal error occurred while parsing.
 // At most one of the two boolean return values will be true.
-func (p *BazelRcParser) parseToken(token string, isLastTokenInLine bool, entriesForCommand map[string][]string, targetAccumulator *[]string, flagNameExpectingValueWithLeadingDashes **string, importCallStack []string, zeroBaseLineNumber int) (bool, bool, error) {
-	if isLastTokenInLine && token == "\\" {
+func (p *BazelRcParser) parseToken(token Token, isLastTokenInLine bool, options *[]Option, targetAccumulator *[]string, flagExpectingValue **pendingFlag, flagAliases map[string]string, importCallStack []string) (bool, bool, error) {
+	if isLastTokenInLine && token.Value == "\\" {
 		return true, false, nil
 	}
 
-	if *flagNameExpectingValueWithLeadingDashes != nil {
-		flagNameExpectingValueWithoutLeadingDashes := stripLeadingDashes(**flagNameExpectingValueWithLeadingDashes)
-		if p.isKnownBooleanFlag(flagNameExpectingValueWithoutLeadingDashes) && token != "true" && token != "false" {
-			if err := p.handleBooleanFlag(**flagNameExpectingValueWithLeadingDashes, entriesForCommand, importCallStack, zeroBaseLineNumber); err != nil {
+	if *flagExpectingValue != nil {
+		pending := **flagExpectingValue
+		flagNameExpectingValueWithoutLeadingDashes := stripLeadingDashes(pending.nameWithLeadingDashes)
+		if p.isKnownBooleanFlag(flagNameExpectingValueWithoutLeadingDashes) && !p.knownFlagData.isExplicitValueForFlagAcceptingNoValue(flagNameExpectingValueWithoutLeadingDashes, token.Value) {
+			if err := p.handleBooleanFlag(pending.nameWithLeadingDashes, pending.token, options, importCallStack); err != nil {
 				return false, false, err
 			}
-			*flagNameExpectingValueWithLeadingDashes = nil
+			*flagExpectingValue = nil
 		} else {
-			entriesForCommand[flagNameExpectingValueWithoutLeadingDashes] = append(entriesForCommand[flagNameExpectingValueWithoutLeadingDashes], token)
-			*flagNameExpectingValueWithLeadingDashes = nil
+			*options = append(*options, Option{
+				Name:      flagNameExpectingValueWithoutLeadingDashes,
+				Value:     token.Value,
+				Tokens:    []Token{pending.token, token},
+				NameSpan:  pending.token.Span,
+				ValueSpan: token.Span,
+			})
+			*flagExpectingValue = nil
 			return false, false, nil
 		}
 	}
 
-	if !strings.HasPrefix(token, "-") {
-		*targetAccumulator = append(*targetAccumulator, token)
+	if !strings.HasPrefix(token.Value, "-") {
+		*targetAccumulator = append(*targetAccumulator, token.Value)
 		return false, false, nil
 	}
 
-	if token == "--" {
-		*targetAccumulator = append(*targetAccumulator, token)
+	if token.Value == "--" {
+		*targetAccumulator = append(*targetAccumulator, token.Value)
 		return false, true, nil
 	}
 
@@ -221,77 +501,107 @@ func (p *BazelRcParser) parseToken(token string, isLastTokenInLine bool, entries
 	// We handle all of these cases here, rather than in the main loop because special-casing
 	// handling of -s-, as well as the fact that = isn't allowed would otherwise overly complicate
 	// the non-abbreviated-flag codepath.
-	if !strings.HasPrefix(token, "--") {
-		fullFlagName, value, fullFlagNameWithLeadingDashes, err := p.parseAsAbbreviatedFlag(token)
+	if !strings.HasPrefix(token.Value, "--") {
+		fullFlagName, value, fullFlagNameWithLeadingDashes, err := p.parseAsAbbreviatedFlag(token, importCallStack)
 		if err != nil {
 			return false, false, err
 		} else if fullFlagNameWithLeadingDashes != "" {
-			*flagNameExpectingValueWithLeadingDashes = &fullFlagNameWithLeadingDashes
+			*flagExpectingValue = &pendingFlag{nameWithLeadingDashes: fullFlagNameWithLeadingDashes, token: token}
 		} else {
-			entriesForCommand[fullFlagName] = append(entriesForCommand[fullFlagName], value)
+			valueSpan := Span{}
+			if value == "false" {
+				// The trailing - of `-s-` is what sets the value.
+				valueSpan = token.spanFrom(2)
+			}
+			*options = append(*options, Option{
+				Name:      fullFlagName,
+				Value:     value,
+				Tokens:    []Token{token},
+				NameSpan:  token.spanBefore(2),
+				ValueSpan: valueSpan,
+			})
 		}
 		return false, false, nil
 	}
 
-	if strings.Contains(token, "=") {
-		parts := strings.SplitN(token, "=", 2)
-		flagName := parts[0]
-
-		flagName = stripLeadingDashes(flagName)
-		flagValue := parts[1]
+	if isStarlarkFlag, err := p.parseStarlarkFlag(token, options, flagAliases, importCallStack); isStarlarkFlag {
+		return false, false, err
+	}
 
-		entriesForCommand[flagName] = append(entriesForCommand[flagName], flagValue)
+	if equalsIndex := strings.Index(token.Value, "="); equalsIndex != -1 {
+		flagName := stripLeadingDashes(token.Value[:equalsIndex])
+		flagValue := token.Value[equalsIndex+1:]
+
+		*options = append(*options, Option{
+			Name:      flagName,
+			Value:     flagValue,
+			Tokens:    []Token{token},
+			NameSpan:  token.spanBefore(equalsIndex),
+			ValueSpan: token.spanFrom(equalsIndex + 1),
+		})
 	} else {
 		if isLastTokenInLine {
-			if err := p.handleBooleanFlag(token, entriesForCommand, importCallStack, zeroBaseLineNumber); err != nil {
+			if err := p.handleBooleanFlag(token.Value, token, options, importCallStack); err != nil {
 				return false, false, err
 			}
 		} else {
-			*flagNameExpectingValueWithLeadingDashes = &token
+			*flagExpectingValue = &pendingFlag{nameWithLeadingDashes: token.Value, token: token}
 		}
 	}
 	return false, false, nil
 }
 
+func tokenValues(tokens []Token) []string {
+	values := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		values = append(values, token.Value)
+	}
+	return values
+}
+
 func (p *BazelRcParser) isKnownBooleanFlag(flagName string) bool {
-	knownBoolean, knownAtAll := p.knownFlagData.BooleanFlags[flagName]
-	if knownAtAll {
-		return knownBoolean
+	if p.knownFlagData.acceptsNoValue(flagName) {
+		return true
+	}
+	if _, knownAtAll := p.knownFlagData.BooleanFlags[flagName]; knownAtAll {
+		return false
 	}
 	if !strings.HasPrefix(flagName, "no") {
 		return false
 	}
-	return p.knownFlagData.BooleanFlags[flagName[2:]]
+	return p.knownFlagData.acceptsNoValue(flagName[2:])
 }
 
 // Returns exactly one of:
 //   - First two return values are a flag name and value.
 //   - Third return value is a full flag name with leading dashes, which is expecting a value.
 //   - Fourth return value is an error.
-func (p *BazelRcParser) parseAsAbbreviatedFlag(token string) (string, string, string, error) {
+func (p *BazelRcParser) parseAsAbbreviatedFlag(abbreviatedToken Token, importCallStack []string) (string, string, string, error) {
+	token := abbreviatedToken.Value
+	location := newSpanLocation(importCallStack, abbreviatedToken.Span)
 	if len(token) < 2 {
-		return "", "", "", fmt.Errorf("%q isn't a valid flag", token)
+		return "", "", "", &UnknownFlagError{Location: location, Token: token, message: fmt.Sprintf("%q isn't a valid flag", token)}
 	}
 	// We assume that all abbreviations are of length exactly 1.
 	// This is verified in our data table generator.
 	possibleAbbreviation := token[1:2]
 	fullFlagName, isAbbreviation := p.knownFlagData.FlagAbbreviations[possibleAbbreviation]
 	if !isAbbreviation {
-		return "", "", "", fmt.Errorf("flag %s wasn't a known abbreviation", possibleAbbreviation)
+		return "", "", "", &UnknownAbbreviationError{Location: location, Abbreviation: possibleAbbreviation}
 	}
 	fullFlagNameWithLeadingDashes := fmt.Sprintf("--%s", fullFlagName)
-	if isBoolean := p.knownFlagData.BooleanFlags[fullFlagName]; isBoolean {
+	if isBoolean := p.knownFlagData.acceptsNoValue(fullFlagName); isBoolean {
 		if len(token) == 2 {
 			return fullFlagName, "true", "", nil
 		} else if len(token) == 3 && token[2:3] == "-" {
 			return fullFlagName, "false", "", nil
 		} else {
-			return "", "", "", fmt.Errorf("token %q wasn't a valid abbreviated flag", token)
+			return "", "", "", &UnknownFlagError{Location: location, Token: token, message: fmt.Sprintf("token %q wasn't a valid abbreviated flag", token)}
 		}
 	} else if len(token) == 2 {
 		return "", "", fullFlagNameWithLeadingDashes, nil
 	} else {
-		return "", "", "", fmt.Errorf("flag %q wasn't a valid abbreviated flag", token)
+		return "", "", "", &UnknownFlagError{Location: location, Token: token, message: fmt.Sprintf("flag %q wasn't a valid abbreviated flag", token)}
 	}
 }
 
@@ -304,33 +614,28 @@ func stripLeadingDashes(flag string) string {
 	return flag
 }
 
-func (p *BazelRcParser) handleBooleanFlag(flag string, flagNamesToValues map[string][]string, importCallStack []string, oneBaseLineNumber int) error {
+// handleBooleanFlag records a flag which wasn't given a value, which is only allowed for boolean flags.
+// flag is the flag's name with leading dashes, and token is where it was found.
+func (p *BazelRcParser) handleBooleanFlag(flag string, token Token, options *[]Option, importCallStack []string) error {
 	assumedValue := "true"
 	flagName := stripLeadingDashes(flag)
 	if strings.HasPrefix(flag, "--no") {
 		assumedValue = "false"
 		flagName = flag[4:]
 	}
-	if requiresValue := !p.knownFlagData.BooleanFlags[flagName]; requiresValue {
-		return makeError(importCallStack, oneBaseLineNumber, fmt.Errorf("value-requiring flag %s didn't have value", flagName), false)
+	if requiresValue := !p.knownFlagData.acceptsNoValue(flagName); requiresValue {
+		return &MissingFlagValueError{Location: newSpanLocation(importCallStack, token.Span), Flag: flagName}
 	}
-	flagNamesToValues[flagName] = append(flagNamesToValues[flagName], assumedValue)
+	*options = append(*options, Option{
+		Name:     flagName,
+		Value:    assumedValue,
+		Tokens:   []Token{token},
+		NameSpan: token.Span,
+	})
 	return nil
 }
 
-func makeError(importCallStack []string, zeroBasedLineNumber int, errorMessage error, handlingImport bool) error {
-	importReason := ""
-	if len(importCallStack)-1 > 1 {
-		importReason = fmt.Sprintf(" - File %s imports file %s", importCallStack[0], strings.Join(importCallStack[1:], " which imports file "))
-	}
-	prefixMessage := ""
-	if !handlingImport {
-		lineDescription := ""
-		if zeroBasedLineNumber >= 0 {
-			lineDescription = fmt.Sprintf(" on line %d", zeroBasedLineNumber+1)
-		}
-		prefixMessage = fmt.Sprintf("failed to process %s%s, ", importCallStack[len(importCallStack)-1], lineDescription)
-	}
-
-	return fmt.Errorf("%s%w%s", prefixMessage, errorMessage, importReason)
+// wrapImportError adds context to an error which was found while parsing the last file in importCallStack.
+func wrapImportError(importCallStack []string, err error) error {
+	return fmt.Errorf("unable to parse import file due to error: %w%s", err, importReason(importCallStack))
 }
@@ -601,7 +601,7 @@ test --something=else`,
 				return
 			}
 			require.NoError(t, err)
-			require.Equal(t, tc.wantOutput, cmd)
+			require.Equal(t, tc.wantOutput.entries, cmd.entries)
 		})
 	}
 }
@@ -625,3 +625,158 @@ func newFile(t *testing.T, directory, basename, contents string) string {
 	require.NoError(t, err)
 	return absolutePath
 }
+
+func TestParsefileCollectingDiagnostics(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "diagnostics")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	importedFile := newFile(t, testDir, "imported-bazelrc", "build --imported=true\nbuild -z\ntest --imported=true")
+
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{
+			"bool_flag": true,
+		},
+		FlagAbbreviations: map[string]string{
+			"j": "jobs",
+		},
+	}
+	parser := NewBazelRcParser(testDir, flagData)
+
+	input := fmt.Sprintf(`build --foo=bar
+build --unterminated="quote
+build --good=before --jobs
+import %s
+build --skipped=true -q \\
+--also_skipped=true
+test --after=true -j
+import /pathdoesnotexist/to/file
+try-import /pathdoesnotexist/to/file
+build --last=true`, importedFile)
+
+	contents, diagnostics := parser.ParsefileCollectingDiagnostics(strings.NewReader(input), "/sample/bazelrc")
+	require.Equal(t, map[string]BazelFlagValues{
+		"build": {
+			"foo":      []string{"bar"},
+			"imported": []string{"true"},
+			"last":     []string{"true"},
+		},
+		"test": {
+			"imported": []string{"true"},
+		},
+	}, contents.entries)
+	require.Equal(t, diagnostics, contents.Diagnostics())
+
+	type summary struct {
+		file string
+		line int
+		err  string
+	}
+	var got []summary
+	for _, diagnostic := range diagnostics {
+		require.Equal(t, SeverityError, diagnostic.Severity)
+		got = append(got, summary{diagnostic.Location.File, diagnostic.Location.Line, fmt.Sprintf("%T", diagnostic.Err)})
+	}
+	require.Equal(t, []summary{
+		{"/sample/bazelrc", 2, "*bazelrc.TokenizeError"},
+		{"/sample/bazelrc", 3, "*bazelrc.MissingFlagValueError"},
+		{importedFile, 2, "*bazelrc.UnknownAbbreviationError"},
+		{"/sample/bazelrc", 5, "*bazelrc.UnknownAbbreviationError"},
+		{"/sample/bazelrc", 7, "*bazelrc.MissingFlagValueError"},
+		{"/sample/bazelrc", 8, "*bazelrc.MissingImportError"},
+	}, got)
+}
+
+func TestOptionPositions(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{
+			"bool_flag": true,
+		},
+		FlagAbbreviations: map[string]string{
+			"b": "bool_flag",
+			"j": "jobs",
+		},
+	}
+	parser := NewBazelRcParser("", flagData)
+
+	input := `# comment
+build:ci --foo="a b" \\
+    --nobool_flag -j \\
+  200
+test -b-`
+	contents, err := parser.Parsefile(strings.NewReader(input), "/sample/bazelrc")
+	require.NoError(t, err)
+
+	at := func(line, column, offset int) Position {
+		return Position{File: "/sample/bazelrc", Line: line, Column: column, Offset: offset, ArgIndex: -1}
+	}
+	type summary struct {
+		command   string
+		name      string
+		value     string
+		nameSpan  Span
+		valueSpan Span
+		span      Span
+	}
+	var got []summary
+	for _, option := range contents.Options() {
+		got = append(got, summary{option.Command, option.Name, option.Value, option.NameSpan, option.ValueSpan, option.Span()})
+	}
+	require.Equal(t, []summary{
+		{
+			command:   "build:ci",
+			name:      "foo",
+			value:     "a b",
+			nameSpan:  Span{at(2, 10, 19), at(2, 15, 24)},
+			valueSpan: Span{at(2, 17, 26), at(2, 21, 30)},
+			span:      Span{at(2, 10, 19), at(2, 21, 30)},
+		},
+		{
+			command:  "build:ci",
+			name:     "bool_flag",
+			value:    "false",
+			nameSpan: Span{at(3, 5, 38), at(3, 18, 51)},
+			span:     Span{at(3, 5, 38), at(3, 18, 51)},
+		},
+		{
+			command:   "build:ci",
+			name:      "jobs",
+			value:     "200",
+			nameSpan:  Span{at(3, 19, 52), at(3, 21, 54)},
+			valueSpan: Span{at(4, 3, 60), at(4, 6, 63)},
+			span:      Span{at(3, 19, 52), at(4, 6, 63)},
+		},
+		{
+			command:   "test",
+			name:      "bool_flag",
+			value:     "false",
+			nameSpan:  Span{at(5, 6, 69), at(5, 8, 71)},
+			valueSpan: Span{at(5, 8, 71), at(5, 9, 72)},
+			span:      Span{at(5, 6, 69), at(5, 9, 72)},
+		},
+	}, got)
+	require.Equal(t, Span{at(2, 1, 10), at(2, 9, 18)}, contents.Options()[0].CommandSpan)
+	require.Equal(t, Span{at(5, 1, 64), at(5, 5, 68)}, contents.Options()[3].CommandSpan)
+}
+
+func TestErrorPositionsInContinuationLines(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{},
+	}
+	parser := NewBazelRcParser("", flagData)
+
+	input := `build --foo=bar \\
+  --baz=qux \\
+  -z`
+	_, err := parser.Parsefile(strings.NewReader(input), "/sample/bazelrc")
+	var abbreviationErr *UnknownAbbreviationError
+	require.ErrorAs(t, err, &abbreviationErr)
+	require.Equal(t, Position{File: "/sample/bazelrc", Line: 3, Column: 3, Offset: 36, ArgIndex: -1}, abbreviationErr.Position)
+	require.Equal(t, Position{File: "/sample/bazelrc", Line: 3, Column: 5, Offset: 38, ArgIndex: -1}, abbreviationErr.End)
+	require.ErrorContains(t, err, "failed to process /sample/bazelrc on line 3, flag z wasn't a known abbreviation")
+
+	_, err = parser.Parsefile(strings.NewReader("build --foo=bar --quoted=\"unterminated"), "/sample/bazelrc")
+	var tokenizeErr *TokenizeError
+	require.ErrorAs(t, err, &tokenizeErr)
+	require.Equal(t, 17, tokenizeErr.Column)
+}
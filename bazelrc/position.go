@@ -0,0 +1,58 @@
+package bazelrc
+
+import "fmt"
+
+// Position identifies a single byte in a bazelrc file or on a command line.
+type Position struct {
+	// File is the path of the bazelrc file. It is empty for command-line positions.
+	File string
+	// Line is the 1-based line number within File. It is 0 for command-line positions.
+	Line int
+	// Column is the 1-based byte column within Line, or within the argument for command-line positions.
+	Column int
+	// Offset is the 0-based byte offset within File, or within the argument for command-line positions.
+	Offset int
+	// ArgIndex is the 0-based index of the command-line argument containing this position. It is -1 for bazelrc positions.
+	ArgIndex int
+}
+
+// IsCommandLine returns whether the position refers to a command-line argument, rather than a bazelrc file.
+func (p Position) IsCommandLine() bool {
+	return p.ArgIndex >= 0 && p.Line == 0
+}
+
+func (p Position) String() string {
+	if p.IsCommandLine() {
+		return fmt.Sprintf("arg %d:%d", p.ArgIndex, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// advance returns the position n bytes after p, which must be on the same line (or in the same argument).
+func (p Position) advance(n int) Position {
+	p.Column += n
+	p.Offset += n
+	return p
+}
+
+// Span is a range of bytes in a bazelrc file or on a command line.
+type Span struct {
+	// Start is the position of the first byte in the span.
+	Start Position
+	// End is the position immediately after the last byte in the span.
+	End Position
+}
+
+func (s Span) String() string {
+	return fmt.Sprintf("%s-%s", s.Start, s.End)
+}
+
+// IsZero returns whether the span is unset, e.g. for values which were implied rather than written.
+func (s Span) IsZero() bool {
+	return s == Span{}
+}
+
+// joinSpans returns the smallest span covering both a and b, which must be in the same file (or argument list) with a no later than b.
+func joinSpans(a Span, b Span) Span {
+	return Span{Start: a.Start, End: b.End}
+}
@@ -0,0 +1,295 @@
+package bazelrc
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Resolver computes the effective options of Bazel invocations from parsed bazelrc files and command lines, the way Bazel's options parser does:
+// options are applied from the bazelrc sections for each command the invoked command inherits from (most general first), then from the command line,
+// with `--config` and expansion flags expanded in place.
+type Resolver struct {
+	contents      *BazelrcContents
+	knownFlagData *FlagData
+	// platformConfig is the config applied when `--enable_platform_specific_config` is set, e.g. "linux".
+	platformConfig string
+	// compatibility selects how flags which don't apply to the invoked command are handled.
+	compatibility BazelCompatibility
+	// optionsByCommand holds the options from contents for each command (including any config name, e.g. "build:ci"), in the order they were parsed.
+	optionsByCommand map[string][]Option
+}
+
+// ResolverOption configures optional behaviour of a Resolver.
+type ResolverOption func(*Resolver)
+
+// WithPlatformConfig sets the name of the config applied when `--enable_platform_specific_config` is set.
+// By default this is the name Bazel uses for the current OS (e.g. "linux", "macos" or "windows").
+func WithPlatformConfig(name string) ResolverOption {
+	return func(r *Resolver) {
+		r.platformConfig = name
+	}
+}
+
+// NewResolver makes a Resolver of options from contents.
+func NewResolver(contents *BazelrcContents, knownFlagData *FlagData, options ...ResolverOption) *Resolver {
+	r := &Resolver{
+		contents:         contents,
+		knownFlagData:    knownFlagData,
+		platformConfig:   platformConfigForOS(runtime.GOOS),
+		optionsByCommand: make(map[string][]Option),
+	}
+	for _, option := range options {
+		option(r)
+	}
+	for _, option := range contents.Options() {
+		r.optionsByCommand[option.Command] = append(r.optionsByCommand[option.Command], option)
+	}
+	return r
+}
+
+// platformConfigForOS returns the name of the config Bazel applies for `--enable_platform_specific_config` on goos.
+func platformConfigForOS(goos string) string {
+	switch goos {
+	case "darwin":
+		return "macos"
+	case "linux", "windows", "freebsd", "openbsd":
+		return goos
+	}
+	return ""
+}
+
+// commandInheritance lists the commands each Bazel command inherits options from, most general first.
+// Commands which aren't listed inherit only from `always` and `common`.
+var commandInheritance = map[string][]string{
+	"test":               {"build"},
+	"run":                {"build"},
+	"coverage":           {"build", "test"},
+	"cquery":             {"build", "test"},
+	"aquery":             {"build"},
+	"info":               {"build"},
+	"print_action":       {"build"},
+	"mobile-install":     {"build"},
+	"fetch":              {"build", "test"},
+	"vendor":             {"build", "test"},
+	"canonicalize-flags": {"build"},
+	"clean":              {"build"},
+}
+
+// CommandChain returns the bazelrc commands whose options apply to an invocation of command, in the order they're applied, e.g. `always`, `common`, `build`, `test` for `test`.
+func CommandChain(command string) []string {
+	chain := []string{"always", "common"}
+	chain = append(chain, commandInheritance[command]...)
+	return append(chain, command)
+}
+
+// ResolvedOption is an option which applies to an invocation.
+type ResolvedOption struct {
+	Option
+	// Via lists the `--config` and expansion options which caused this option to be applied, outermost first.
+	// It is empty for options which were applied directly.
+	Via []Option
+}
+
+// ResolvedInvocation holds the effective options of an invocation.
+type ResolvedInvocation struct {
+	// Command is the invoked command, e.g. "test".
+	Command string
+	// Options holds every applied option in the order it was applied, so later options override earlier ones.
+	// `--config` and expansion flags are replaced by the options they expand to.
+	// Options which don't apply to Command are omitted.
+	Options []ResolvedOption
+	// Diagnostics holds any warnings found while resolving.
+	Diagnostics []Diagnostic
+}
+
+// Values returns the values of every option, in the order they were applied.
+func (i *ResolvedInvocation) Values() BazelFlagValues {
+	values := make(BazelFlagValues)
+	for _, option := range i.Options {
+		values[option.Name] = append(values[option.Name], option.Value)
+	}
+	return values
+}
+
+// FlagValue gets the effective (i.e. last) value for a particular flag.
+// It has no awareness of what flags are allowed multiple values, or default values.
+func (i *ResolvedInvocation) FlagValue(flagName string) *string {
+	return i.Values().FlagValue(flagName)
+}
+
+// UndefinedConfigError is returned when `--config` names a config which isn't defined for any command which applies to the invocation.
+type UndefinedConfigError struct {
+	// Location is the `--config` option.
+	Location
+	Config string
+}
+
+func (e *UndefinedConfigError) Error() string {
+	return e.describeSelf(fmt.Sprintf("config value '%s' is not defined in any .rc file", e.Config))
+}
+
+// ExpansionCycleError is returned when a `--config` or expansion flag expands (possibly indirectly) to itself.
+type ExpansionCycleError struct {
+	// Location is the option which would repeat the cycle.
+	Location
+	// Cycle lists the options in the cycle, starting and ending with the same option, e.g. `--config=a --config=b --config=a`.
+	Cycle []string
+}
+
+func (e *ExpansionCycleError) Error() string {
+	return e.describeSelf(fmt.Sprintf("expansion cycle detected: %s", strings.Join(e.Cycle, " -> ")))
+}
+
+// Resolve computes the effective options of an invocation of command, with the given options from the command line (e.g. from ParseCommandLineArgsAfterCommand).
+func (r *Resolver) Resolve(command string, commandLineOptions []Option) (*ResolvedInvocation, error) {
+	state := &resolution{
+		resolver:            r,
+		command:             command,
+		commands:            CommandChain(command),
+		platformConfigIndex: -1,
+	}
+	for _, rcCommand := range state.commands {
+		for _, option := range r.optionsByCommand[rcCommand] {
+			if err := state.apply(option, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, option := range commandLineOptions {
+		if err := state.apply(option, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := state.applyPlatformConfig(); err != nil {
+		return nil, err
+	}
+	return &ResolvedInvocation{Command: command, Options: state.options, Diagnostics: state.diagnostics}, nil
+}
+
+// resolution holds the state of a single call to Resolve.
+type resolution struct {
+	resolver *Resolver
+	// command is the invoked command.
+	command string
+	// commands are the bazelrc commands which apply, from CommandChain.
+	commands    []string
+	options     []ResolvedOption
+	diagnostics []Diagnostic
+	// platformConfigIndex is the index in options after the last option which enabled `--enable_platform_specific_config`, or -1 if it isn't enabled.
+	platformConfigIndex int
+	// platformConfigEnabledBy is the option which enabled `--enable_platform_specific_config`.
+	platformConfigEnabledBy ResolvedOption
+}
+
+// apply applies option, expanding it if it's a `--config` or expansion flag.
+// via lists the options which caused it to be applied.
+func (s *resolution) apply(option Option, via []Option) error {
+	if applies, err := s.checkApplicability(option); !applies {
+		return err
+	}
+	if option.Name == "config" {
+		return s.expandConfig(option, via)
+	}
+	if expansion, ok := s.resolver.knownFlagData.expansion(option.Name); ok {
+		return s.expandFlag(option, expansion, via)
+	}
+	s.options = append(s.options, ResolvedOption{Option: option, Via: via})
+	if option.Name == "enable_platform_specific_config" {
+		if option.Value == "true" {
+			s.platformConfigIndex = len(s.options)
+			s.platformConfigEnabledBy = s.options[len(s.options)-1]
+		} else {
+			s.platformConfigIndex = -1
+		}
+	}
+	return nil
+}
+
+// checkExpansionCycle returns an ExpansionCycleError if option already appears in via.
+func checkExpansionCycle(option Option, via []Option) error {
+	for i, outer := range via {
+		if outer.Name == option.Name && outer.Value == option.Value {
+			var cycle []string
+			for _, inCycle := range via[i:] {
+				cycle = append(cycle, describeExpandingOption(inCycle))
+			}
+			return &ExpansionCycleError{
+				Location: newSpanLocation(nil, option.Span()),
+				Cycle:    append(cycle, describeExpandingOption(option)),
+			}
+		}
+	}
+	return nil
+}
+
+// describeExpandingOption describes a `--config` or expansion flag, e.g. as `--config=ci`.
+func describeExpandingOption(option Option) string {
+	if option.Name == "config" {
+		return fmt.Sprintf("--config=%s", option.Value)
+	}
+	return fmt.Sprintf("--%s", option.Name)
+}
+
+// expandConfig applies the options in the sections for config of each applicable command, in the order of CommandChain.
+func (s *resolution) expandConfig(option Option, via []Option) error {
+	if err := checkExpansionCycle(option, via); err != nil {
+		return err
+	}
+	innerVia := appendVia(via, option)
+	defined := false
+	for _, command := range s.commands {
+		configOptions, ok := s.resolver.optionsByCommand[command+":"+option.Value]
+		if !ok {
+			continue
+		}
+		defined = true
+		for _, configOption := range configOptions {
+			if err := s.apply(configOption, innerVia); err != nil {
+				return err
+			}
+		}
+	}
+	if !defined {
+		return &UndefinedConfigError{Location: newSpanLocation(nil, option.Span()), Config: option.Value}
+	}
+	return nil
+}
+
+// applyPlatformConfig expands the platform-specific config, if `--enable_platform_specific_config` is set and the config is defined.
+// The config's options are applied as if they immediately followed the option which enabled it.
+func (s *resolution) applyPlatformConfig() error {
+	platformConfig := s.resolver.platformConfig
+	if s.platformConfigIndex == -1 || platformConfig == "" || !s.resolver.isConfigDefined(platformConfig, s.commands) {
+		return nil
+	}
+	enabling := s.platformConfigEnabledBy
+	config := Option{
+		Command: enabling.Command,
+		Name:    "config",
+		Value:   platformConfig,
+		Tokens:  enabling.Tokens,
+	}
+	following := append([]ResolvedOption(nil), s.options[s.platformConfigIndex:]...)
+	s.options = s.options[:s.platformConfigIndex]
+	if err := s.expandConfig(config, appendVia(enabling.Via, enabling.Option)); err != nil {
+		return err
+	}
+	s.options = append(s.options, following...)
+	return nil
+}
+
+// isConfigDefined returns whether config is defined for any of commands.
+func (r *Resolver) isConfigDefined(config string, commands []string) bool {
+	for _, command := range commands {
+		if _, ok := r.optionsByCommand[command+":"+config]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// appendVia returns a copy of via with option appended, so that slices shared between options aren't modified.
+func appendVia(via []Option, option Option) []Option {
+	return append(append(make([]Option, 0, len(via)+1), via...), option)
+}
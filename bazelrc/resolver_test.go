@@ -0,0 +1,196 @@
+package bazelrc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{
+			"enable_platform_specific_config": true,
+			"announce_rc":                     true,
+			"keep_going":                      true,
+			"fast_build":                      true,
+			"internal_spawn_scheduler":        true,
+		},
+		Expansions: map[string][]string{
+			"fast_build": {"--jobs=100", "--config=remote"},
+		},
+	}
+
+	rcContents := `common --color=yes
+build --jobs=10
+test --test_output=errors
+always --announce_rc
+build --keep_going
+build:ci --config=remote --jobs=20
+test:ci --test_output=all
+build:remote --remote_cache=grpc://cache
+test:only_test --test_output=summary
+build:linux --platform_suffix=linux
+build:macos --platform_suffix=macos
+build:a --config=b
+build:b --config=a
+build:spawn --experimental_spawn_scheduler
+`
+
+	for name, tc := range map[string]struct {
+		command     string
+		commandLine []string
+		options     []ResolverOption
+		want        BazelFlagValues
+		wantErr     func(t *testing.T, err error)
+	}{
+		"inherited commands in order": {
+			command: "test",
+			want: BazelFlagValues{
+				"announce_rc": []string{"true"},
+				"color":       []string{"yes"},
+				"jobs":        []string{"10"},
+				"keep_going":  []string{"true"},
+				"test_output": []string{"errors"},
+			},
+		},
+		"unrelated command sections don't apply": {
+			command: "query",
+			want: BazelFlagValues{
+				"announce_rc": []string{"true"},
+				"color":       []string{"yes"},
+			},
+		},
+		"configs expand in place for each applicable command": {
+			command:     "test",
+			commandLine: []string{"--config=ci", "--jobs=5"},
+			want: BazelFlagValues{
+				"announce_rc":  []string{"true"},
+				"color":        []string{"yes"},
+				"jobs":         []string{"10", "20", "5"},
+				"keep_going":   []string{"true"},
+				"test_output":  []string{"errors", "all"},
+				"remote_cache": []string{"grpc://cache"},
+			},
+		},
+		"config only defined for another command": {
+			command:     "build",
+			commandLine: []string{"--config=only_test"},
+			wantErr: func(t *testing.T, err error) {
+				var undefinedErr *UndefinedConfigError
+				require.True(t, errors.As(err, &undefinedErr))
+				require.Equal(t, "only_test", undefinedErr.Config)
+				require.Equal(t, 0, undefinedErr.ArgIndex)
+				require.EqualError(t, err, "config value 'only_test' is not defined in any .rc file")
+			},
+		},
+		"config cycle": {
+			command:     "build",
+			commandLine: []string{"--config=a"},
+			wantErr: func(t *testing.T, err error) {
+				var cycleErr *ExpansionCycleError
+				require.True(t, errors.As(err, &cycleErr))
+				require.Equal(t, []string{"--config=a", "--config=b", "--config=a"}, cycleErr.Cycle)
+				require.Equal(t, "/sample/bazelrc", cycleErr.File)
+				require.Equal(t, 13, cycleErr.Line)
+			},
+		},
+		"platform specific config": {
+			command:     "build",
+			commandLine: []string{"--enable_platform_specific_config", "--platform_suffix=cli"},
+			options:     []ResolverOption{WithPlatformConfig("linux")},
+			want: BazelFlagValues{
+				"announce_rc":                     []string{"true"},
+				"color":                           []string{"yes"},
+				"jobs":                            []string{"10"},
+				"keep_going":                      []string{"true"},
+				"enable_platform_specific_config": []string{"true"},
+				"platform_suffix":                 []string{"linux", "cli"},
+			},
+		},
+		"platform specific config disabled again": {
+			command:     "build",
+			commandLine: []string{"--enable_platform_specific_config", "--noenable_platform_specific_config"},
+			options:     []ResolverOption{WithPlatformConfig("macos")},
+			want: BazelFlagValues{
+				"announce_rc":                     []string{"true"},
+				"color":                           []string{"yes"},
+				"jobs":                            []string{"10"},
+				"keep_going":                      []string{"true"},
+				"enable_platform_specific_config": []string{"true", "false"},
+			},
+		},
+		"platform specific config not defined": {
+			command:     "build",
+			commandLine: []string{"--enable_platform_specific_config"},
+			options:     []ResolverOption{WithPlatformConfig("windows")},
+			want: BazelFlagValues{
+				"announce_rc":                     []string{"true"},
+				"color":                           []string{"yes"},
+				"jobs":                            []string{"10"},
+				"keep_going":                      []string{"true"},
+				"enable_platform_specific_config": []string{"true"},
+			},
+		},
+		"expansion flags": {
+			command:     "build",
+			commandLine: []string{"--fast_build", "--config=spawn"},
+			want: BazelFlagValues{
+				"announce_rc":              []string{"true"},
+				"color":                    []string{"yes"},
+				"jobs":                     []string{"10", "100"},
+				"keep_going":               []string{"true"},
+				"remote_cache":             []string{"grpc://cache"},
+				"internal_spawn_scheduler": []string{"true"},
+				"spawn_strategy":           []string{"dynamic"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			contents, err := NewBazelRcParser("", flagData).Parsefile(strings.NewReader(rcContents), "/sample/bazelrc")
+			require.NoError(t, err)
+			commandLine, err := ParseCommandLineArgsAfterCommand(flagData, tc.commandLine)
+			require.NoError(t, err)
+			resolved, err := NewResolver(contents, flagData, tc.options...).Resolve(tc.command, commandLine.Options)
+			if tc.wantErr != nil {
+				tc.wantErr(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.command, resolved.Command)
+			require.Equal(t, tc.want, resolved.Values())
+		})
+	}
+}
+
+func TestResolvedOptionProvenance(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{"enable_platform_specific_config": true},
+	}
+	contents, err := NewBazelRcParser("", flagData).Parsefile(strings.NewReader(`build --enable_platform_specific_config
+build:linux --config=remote
+build:remote --remote_download_minimal
+build --jobs=10`), "/sample/bazelrc")
+	require.NoError(t, err)
+	resolved, err := NewResolver(contents, flagData, WithPlatformConfig("linux")).Resolve("build", nil)
+	require.NoError(t, err)
+
+	require.Len(t, resolved.Options, 3)
+	require.Equal(t, "enable_platform_specific_config", resolved.Options[0].Name)
+	require.Empty(t, resolved.Options[0].Via)
+
+	downloadOutputs := resolved.Options[1]
+	require.Equal(t, "remote_download_outputs", downloadOutputs.Name)
+	require.Equal(t, "minimal", downloadOutputs.Value)
+	require.Equal(t, "build:remote", downloadOutputs.Command)
+	require.Equal(t, 3, downloadOutputs.Span().Start.Line)
+	var via []string
+	for _, option := range downloadOutputs.Via {
+		via = append(via, option.Name+"="+option.Value)
+	}
+	require.Equal(t, []string{"enable_platform_specific_config=true", "config=linux", "config=remote", "remote_download_minimal=true"}, via)
+
+	require.Equal(t, "jobs", resolved.Options[2].Name)
+	require.Equal(t, "10", *resolved.FlagValue("jobs"))
+}
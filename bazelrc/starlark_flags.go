@@ -0,0 +1,146 @@
+package bazelrc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuildSettingType is the type of a Starlark build setting, i.e. which rule defines it.
+type BuildSettingType string
+
+const (
+	BuildSettingBool       BuildSettingType = "bool"
+	BuildSettingInt        BuildSettingType = "int"
+	BuildSettingString     BuildSettingType = "string"
+	BuildSettingStringList BuildSettingType = "string_list"
+	BuildSettingLabel      BuildSettingType = "label"
+)
+
+// IsStarlarkFlag returns whether flagName (without leading dashes) names a Starlark build setting (e.g. `//pkg:flag` or `@repo//:flag`) rather than a native Bazel flag.
+func IsStarlarkFlag(flagName string) bool {
+	return strings.HasPrefix(flagName, "//") || strings.HasPrefix(flagName, "@")
+}
+
+// NormalizeLabel converts label to the form used as the name of Starlark flags in parsed output, so that different spellings of the same build setting are recognised as the same flag:
+//   - Labels in the main repository drop any repository prefix, so `@@//pkg:flag` and `@//pkg:flag` become `//pkg:flag`.
+//   - Labels without a target name get the implied one, so `//pkg/sub` becomes `//pkg/sub:sub` and `@repo` becomes `@repo//:repo`.
+//
+// Labels in other repositories keep their repository prefix as written, as apparent and canonical repository names can't be related without knowledge of the module graph.
+func NormalizeLabel(label string) string {
+	if strings.HasPrefix(label, "@@//") {
+		label = label[2:]
+	} else if strings.HasPrefix(label, "@//") {
+		label = label[1:]
+	}
+	repository, packageAndName, hasPackage := strings.Cut(label, "//")
+	if !hasPackage {
+		if repositoryName := strings.TrimLeft(label, "@"); repositoryName != "" {
+			return label + "//:" + repositoryName
+		}
+		return label
+	}
+	if strings.Contains(packageAndName, ":") {
+		return label
+	}
+	name := packageAndName[strings.LastIndex(packageAndName, "/")+1:]
+	if name == "" {
+		return label
+	}
+	return repository + "//" + packageAndName + ":" + name
+}
+
+// buildSettingType looks up the type of the build setting with the given normalized label in BuildSettings, whose keys may be written in any form.
+func (d *FlagData) buildSettingType(label string) (BuildSettingType, bool) {
+	if d == nil {
+		return "", false
+	}
+	if settingType, ok := d.BuildSettings[label]; ok {
+		return settingType, true
+	}
+	for key, settingType := range d.BuildSettings {
+		if NormalizeLabel(key) == label {
+			return settingType, true
+		}
+	}
+	return "", false
+}
+
+// splitStarlarkFlag returns the label of a token like `--//pkg:flag` or `--no//pkg:flag`, and whether it was negated.
+// Names in flagAliases (e.g. `--fast` or `--nofast`) are replaced by the label they alias.
+// ok is false if the token isn't a Starlark flag.
+func splitStarlarkFlag(flagNameWithLeadingDashes string, flagAliases map[string]string) (label string, negated bool, ok bool) {
+	if !strings.HasPrefix(flagNameWithLeadingDashes, "--") {
+		return "", false, false
+	}
+	flagName := flagNameWithLeadingDashes[2:]
+	if aliased, isAlias := flagAliases[flagName]; isAlias {
+		return aliased, false, true
+	}
+	if IsStarlarkFlag(flagName) {
+		return flagName, false, true
+	}
+	withoutNo := strings.TrimPrefix(flagName, "no")
+	if withoutNo == flagName {
+		return "", false, false
+	}
+	if aliased, isAlias := flagAliases[withoutNo]; isAlias {
+		return aliased, true, true
+	}
+	if IsStarlarkFlag(withoutNo) {
+		return withoutNo, true, true
+	}
+	return "", false, false
+}
+
+// parseStarlarkFlag records a Starlark flag, which unlike native flags never takes its value from the following token:
+// `--//pkg:flag` and `--no//pkg:flag` set a boolean build setting to true and false respectively, and all other build settings need a value given with `=`.
+// Build settings whose type isn't in BuildSettings are assumed to be boolean when given no value.
+// Returns false if token isn't a Starlark flag, or an alias of one.
+func (p *BazelRcParser) parseStarlarkFlag(token Token, options *[]Option, flagAliases map[string]string, importCallStack []string) (bool, error) {
+	flagWithLeadingDashes, value, hasValue := strings.Cut(token.Value, "=")
+	label, negated, ok := splitStarlarkFlag(flagWithLeadingDashes, flagAliases)
+	if !ok {
+		return false, nil
+	}
+	location := newSpanLocation(importCallStack, token.Span)
+	label = NormalizeLabel(label)
+	settingType, knownType := p.knownFlagData.buildSettingType(label)
+
+	if negated {
+		if hasValue {
+			return true, &UnknownFlagError{Location: location, Token: token.Value, message: fmt.Sprintf("negated Starlark flag %q can't be given a value", flagWithLeadingDashes)}
+		}
+		if knownType && settingType != BuildSettingBool {
+			return true, &UnknownFlagError{Location: location, Token: token.Value, message: fmt.Sprintf("Starlark flag %s is a %s build setting, so can't be negated", label, settingType)}
+		}
+		*options = append(*options, Option{
+			Name:     label,
+			Value:    "false",
+			Tokens:   []Token{token},
+			NameSpan: token.Span,
+		})
+		return true, nil
+	}
+
+	if !hasValue {
+		if knownType && settingType != BuildSettingBool {
+			return true, &MissingFlagValueError{Location: location, Flag: label}
+		}
+		*options = append(*options, Option{
+			Name:     label,
+			Value:    "true",
+			Tokens:   []Token{token},
+			NameSpan: token.Span,
+		})
+		return true, nil
+	}
+
+	*options = append(*options, Option{
+		Name:      label,
+		Value:     value,
+		Tokens:    []Token{token},
+		NameSpan:  token.spanBefore(len(flagWithLeadingDashes)),
+		ValueSpan: token.spanFrom(len(flagWithLeadingDashes) + 1),
+	})
+	return true, nil
+}
@@ -0,0 +1,175 @@
+package bazelrc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeLabel(t *testing.T) {
+	for label, want := range map[string]string{
+		"//pkg:flag":          "//pkg:flag",
+		"@//pkg:flag":         "//pkg:flag",
+		"@@//pkg:flag":        "//pkg:flag",
+		"//pkg":               "//pkg:pkg",
+		"//pkg/sub":           "//pkg/sub:sub",
+		"@@//pkg/sub":         "//pkg/sub:sub",
+		"//:flag":             "//:flag",
+		"@repo//pkg:flag":     "@repo//pkg:flag",
+		"@repo//pkg":          "@repo//pkg:pkg",
+		"@@repo+//pkg:flag":   "@@repo+//pkg:flag",
+		"@repo":               "@repo//:repo",
+		"@@rules_foo~1.0":     "@@rules_foo~1.0//:rules_foo~1.0",
+		"//pkg:name/with/dir": "//pkg:name/with/dir",
+	} {
+		t.Run(label, func(t *testing.T) {
+			require.Equal(t, want, NormalizeLabel(label))
+		})
+	}
+}
+
+func TestStarlarkFlags(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags: map[string]bool{
+			"verbose_failures": true,
+			"jobs":             false,
+		},
+		BuildSettings: map[string]BuildSettingType{
+			"//pkg:enable_feature": BuildSettingBool,
+			"@@//pkg:mode":         BuildSettingString,
+			"@rules_foo//:level":   BuildSettingInt,
+		},
+	}
+
+	for name, tc := range map[string]struct {
+		input       string
+		wantEntries map[string]BazelFlagValues
+		wantTargets []string
+		wantErr     error
+	}{
+		"with value": {
+			input: "build --//pkg:mode=fast --@rules_foo//:level=3",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {
+					"//pkg:mode":         []string{"fast"},
+					"@rules_foo//:level": []string{"3"},
+				},
+			},
+		},
+		"boolean without value doesn't consume the next token": {
+			input: "build --//pkg:enable_feature //some:target --jobs 10",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {
+					"//pkg:enable_feature": []string{"true"},
+					"jobs":                 []string{"10"},
+				},
+			},
+			wantTargets: []string{"//some:target"},
+		},
+		"negated boolean": {
+			input: "build --no//pkg:enable_feature --no@//pkg:unknown",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {
+					"//pkg:enable_feature": []string{"false"},
+					"//pkg:unknown":        []string{"false"},
+				},
+			},
+		},
+		"unknown type without value is assumed boolean": {
+			input: "build --@other//flags:thing --verbose_failures",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {
+					"@other//flags:thing": []string{"true"},
+					"verbose_failures":    []string{"true"},
+				},
+			},
+		},
+		"labels are normalized": {
+			input: "build --@@//pkg:mode=a --@//pkg:mode=b --//pkg:mode=c --//pkg=d",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {
+					"//pkg:mode": []string{"a", "b", "c"},
+					"//pkg:pkg":  []string{"d"},
+				},
+			},
+		},
+		"value after a native flag expecting one": {
+			input: "build --jobs --//pkg:enable_feature",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {
+					"jobs": []string{"--//pkg:enable_feature"},
+				},
+			},
+		},
+		"non-boolean without value": {
+			input:   "build --//pkg:mode fast",
+			wantErr: &MissingFlagValueError{Flag: "//pkg:mode"},
+		},
+		"negated non-boolean": {
+			input:   "build --no@rules_foo//:level",
+			wantErr: &UnknownFlagError{Token: "--no@rules_foo//:level"},
+		},
+		"negated with value": {
+			input:   "build --no//pkg:enable_feature=true",
+			wantErr: &UnknownFlagError{Token: "--no//pkg:enable_feature=true"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			parser := NewBazelRcParser("", flagData)
+			contents, err := parser.Parsefile(strings.NewReader(tc.input), "/sample/bazelrc")
+			if tc.wantErr != nil {
+				requireErrorMatches(t, tc.wantErr, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantEntries, contents.entries)
+			}
+
+			cmd, err := ParseCommandLineArgsAfterCommand(flagData, strings.Fields(tc.input)[1:])
+			if tc.wantErr != nil {
+				requireErrorMatches(t, tc.wantErr, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantEntries["build"], cmd.BazelFlags)
+				require.Equal(t, tc.wantTargets, cmd.Targets)
+			}
+		})
+	}
+}
+
+// requireErrorMatches checks that err is of the same type as want, and has the same flag or token.
+func requireErrorMatches(t *testing.T, want error, err error) {
+	t.Helper()
+	switch want := want.(type) {
+	case *MissingFlagValueError:
+		var missingValueErr *MissingFlagValueError
+		require.True(t, errors.As(err, &missingValueErr), "got error %v", err)
+		require.Equal(t, want.Flag, missingValueErr.Flag)
+	case *UnknownFlagError:
+		var unknownFlagErr *UnknownFlagError
+		require.True(t, errors.As(err, &unknownFlagErr), "got error %v", err)
+		require.Equal(t, want.Token, unknownFlagErr.Token)
+	default:
+		t.Fatalf("unexpected error type %T", want)
+	}
+}
+
+func TestStarlarkFlagPositions(t *testing.T) {
+	parser := NewBazelRcParser("", &FlagData{})
+	contents, err := parser.Parsefile(strings.NewReader("build --@@//pkg:mode=fast --no//pkg:on"), "/sample/bazelrc")
+	require.NoError(t, err)
+	options := contents.Options()
+	require.Len(t, options, 2)
+
+	require.Equal(t, "//pkg:mode", options[0].Name)
+	require.Equal(t, 7, options[0].NameSpan.Start.Column)
+	require.Equal(t, 21, options[0].NameSpan.End.Column)
+	require.Equal(t, 22, options[0].ValueSpan.Start.Column)
+	require.Equal(t, 26, options[0].ValueSpan.End.Column)
+
+	require.Equal(t, "//pkg:on", options[1].Name)
+	require.Equal(t, "false", options[1].Value)
+	require.Equal(t, options[1].Tokens[0].Span, options[1].NameSpan)
+	require.True(t, options[1].ValueSpan.IsZero())
+}
@@ -0,0 +1,115 @@
+package bazelrc
+
+import (
+	"fmt"
+)
+
+// StartupOptions holds startup options (those which appear before the command, e.g. `--output_base`).
+type StartupOptions struct {
+	// Options holds every startup option in the order Bazel applies it, so later options override earlier ones.
+	Options []Option
+}
+
+// Values returns the values of every option, in the order they were applied.
+func (s *StartupOptions) Values() BazelFlagValues {
+	values := make(BazelFlagValues)
+	for _, option := range s.Options {
+		values[option.Name] = append(values[option.Name], option.Value)
+	}
+	return values
+}
+
+// FlagValue gets the effective (i.e. last) value for a particular startup option.
+// It has no awareness of what flags are allowed multiple values, or default values.
+func (s *StartupOptions) FlagValue(flagName string) *string {
+	return s.Values().FlagValue(flagName)
+}
+
+// OutputBase returns the value of `--output_base`, or empty if it isn't set.
+func (s *StartupOptions) OutputBase() string {
+	return s.lastValue("output_base")
+}
+
+// OutputUserRoot returns the value of `--output_user_root`, or empty if it isn't set.
+func (s *StartupOptions) OutputUserRoot() string {
+	return s.lastValue("output_user_root")
+}
+
+// HostJvmArgs returns every value of `--host_jvm_args`, in order.
+func (s *StartupOptions) HostJvmArgs() []string {
+	return s.Values()["host_jvm_args"]
+}
+
+func (s *StartupOptions) lastValue(flagName string) string {
+	if value := s.FlagValue(flagName); value != nil {
+		return *value
+	}
+	return ""
+}
+
+// StartupOptions returns the options from `startup` lines, in the order they were encountered.
+func (c *BazelrcContents) StartupOptions() *StartupOptions {
+	startupOptions := &StartupOptions{}
+	for _, option := range c.options {
+		if option.Command == "startup" {
+			startupOptions.Options = append(startupOptions.Options, option)
+		}
+	}
+	return startupOptions
+}
+
+// CommandLineStartupArgs represents the startup options and command of a Bazel command line.
+type CommandLineStartupArgs struct {
+	// Options contains every startup option in the order it was found, with spans identifying the arguments it came from.
+	Options []Option
+	// Command is the first argument which isn't a startup option (e.g. "build"), or empty if there is none.
+	Command string
+	// ArgsAfterCommand contains the arguments following Command, which may be passed to ParseCommandLineArgsAfterCommand.
+	ArgsAfterCommand []string
+}
+
+// ParseCommandLineStartupArgs parses the arguments of a Bazel command line up to and including the command.
+// This function expects to be given every argument after the Bazel binary (i.e. for `bazel --host_jvm_debug build //blah` it should be passed `["--host_jvm_debug", "build", "//blah"]`).
+// Startup options are parsed using knownFlagData.StartupOptions if it's set, and knownFlagData otherwise.
+func ParseCommandLineStartupArgs(knownFlagData *FlagData, args []string) (*CommandLineStartupArgs, error) {
+	parser := NewBazelRcParser("", knownFlagData).parserForCommand("startup")
+	var options []Option
+	var commandAccumulator []string
+	var flagExpectingValue *pendingFlag
+	flagAliases := make(map[string]string)
+	ret := &CommandLineStartupArgs{}
+	for i, arg := range args {
+		if _, _, err := parser.parseToken(newArgToken(arg, i), i+1 == len(args), &options, &commandAccumulator, &flagExpectingValue, flagAliases, nil); err != nil {
+			return nil, fmt.Errorf("failed to parse bazel startup options: %w", err)
+		}
+		if len(commandAccumulator) != 0 {
+			ret.Command = commandAccumulator[0]
+			ret.ArgsAfterCommand = args[i+1:]
+			break
+		}
+	}
+	if flagExpectingValue != nil {
+		return nil, fmt.Errorf("failed to parse bazel startup options: %w", &MissingFlagValueError{Location: newSpanLocation(nil, flagExpectingValue.token.Span), Flag: stripLeadingDashes(flagExpectingValue.nameWithLeadingDashes)})
+	}
+	if err := parser.normalizeOptionValues(options, nil); err != nil {
+		return nil, fmt.Errorf("failed to parse bazel startup options: %w", err)
+	}
+	ret.Options = options
+	return ret, nil
+}
+
+// MergeStartupOptions combines the startup options from bazelrc files with those from the command line, in the order Bazel applies them:
+// options from each of rcContents in turn (which should be given in the order Bazel reads them, i.e. the system, workspace, home and `--bazelrc` files),
+// followed by options from the command line.
+// If the command line sets `--ignore_all_rc_files`, options from rcContents are ignored, as Bazel wouldn't read those files.
+func MergeStartupOptions(commandLineOptions []Option, rcContents ...*BazelrcContents) *StartupOptions {
+	commandLine := &StartupOptions{Options: commandLineOptions}
+	merged := &StartupOptions{}
+	if ignore := commandLine.FlagValue("ignore_all_rc_files"); ignore == nil || *ignore != "true" {
+		for _, contents := range rcContents {
+			merged.Options = append(merged.Options, contents.StartupOptions().Options...)
+		}
+	}
+	merged.Options = append(merged.Options, commandLineOptions...)
+	return merged
+}
@@ -0,0 +1,141 @@
+package bazelrc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bazelbuild/rules_go/go/runfiles"
+	"github.com/stretchr/testify/require"
+)
+
+func startupTestFlagData(t *testing.T) *FlagData {
+	helpOutputPath, err := runfiles.Rlocation("bazelrc_parser_go/bazelrc/testdata/help_startup_options.txt")
+	require.NoError(t, err)
+	flagData, err := GetFlagDataFromHelpOutputFiles(helpOutputPath)
+	require.NoError(t, err)
+	// Command options which share names with startup options, but have different types.
+	flagData.BooleanFlags = map[string]bool{"keep_going": true}
+	flagData.ValueTypes = map[string]ValueType{"batch": ValueTypeInteger}
+	return flagData
+}
+
+func TestStartupOptionsSchema(t *testing.T) {
+	flagData := startupTestFlagData(t)
+	require.NotNil(t, flagData.StartupOptions)
+	require.Equal(t, ValueTypeBoolean, flagData.StartupOptions.ValueType("batch"))
+	require.Equal(t, ValueTypeInteger, flagData.StartupOptions.ValueType("max_idle_secs"))
+	require.True(t, flagData.StartupOptions.AllowsMultiple["host_jvm_args"])
+	require.NotContains(t, flagData.ValueTypes, "max_idle_secs")
+
+	buildHelpOutputPath, err := runfiles.Rlocation("bazelrc_parser_go/bazelrc/testdata/help_build_long.txt")
+	require.NoError(t, err)
+	buildFlagData, err := GetFlagDataFromHelpOutputFiles(buildHelpOutputPath)
+	require.NoError(t, err)
+	require.Equal(t, ValueTypeBoolean, buildFlagData.StartupOptions.ValueType("autodetect_server_javabase"))
+	require.NotContains(t, buildFlagData.BooleanFlags, "autodetect_server_javabase")
+}
+
+func TestStartupLinesUseStartupSchema(t *testing.T) {
+	flagData := startupTestFlagData(t)
+	contents, err := NewBazelRcParser("", flagData).Parsefile(strings.NewReader(`startup --batch --max_idle_secs=0060 --host_jvm_args=-Xmx2g
+build --batch=3 --keep_going
+startup --output_base /tmp/out --host_jvm_args=-Xss4m --nohome_rc
+`), "/sample/bazelrc")
+	require.NoError(t, err)
+	require.Equal(t, map[string]BazelFlagValues{
+		"startup": {
+			"batch":         []string{"true"},
+			"max_idle_secs": []string{"60"},
+			"host_jvm_args": []string{"-Xmx2g", "-Xss4m"},
+			"output_base":   []string{"/tmp/out"},
+			"home_rc":       []string{"false"},
+		},
+		"build": {
+			"batch":      []string{"3"},
+			"keep_going": []string{"true"},
+		},
+	}, contents.entries)
+
+	startupOptions := contents.StartupOptions()
+	require.Len(t, startupOptions.Options, 6)
+	require.Equal(t, "/tmp/out", startupOptions.OutputBase())
+	require.Equal(t, "", startupOptions.OutputUserRoot())
+	require.Equal(t, []string{"-Xmx2g", "-Xss4m"}, startupOptions.HostJvmArgs())
+	require.Equal(t, 3, startupOptions.Options[5].Span().Start.Line)
+
+	_, err = NewBazelRcParser("", flagData).Parsefile(strings.NewReader("startup --max_idle_secs=soon"), "/sample/bazelrc")
+	require.EqualError(t, err, `failed to process /sample/bazelrc on line 1, invalid value "soon" for integer flag max_idle_secs: expected an integer`)
+}
+
+func TestParseCommandLineStartupArgs(t *testing.T) {
+	flagData := startupTestFlagData(t)
+	for name, tc := range map[string]struct {
+		args    []string
+		want    BazelFlagValues
+		command string
+		rest    []string
+		wantErr string
+	}{
+		"no startup options": {
+			args:    []string{"build", "//foo", "--batch=1"},
+			want:    BazelFlagValues{},
+			command: "build",
+			rest:    []string{"//foo", "--batch=1"},
+		},
+		"boolean and separate values": {
+			args: []string{"--batch", "--output_user_root", "/tmp/root", "--nosystem_rc", "test", "--keep_going"},
+			want: BazelFlagValues{
+				"batch":            []string{"true"},
+				"output_user_root": []string{"/tmp/root"},
+				"system_rc":        []string{"false"},
+			},
+			command: "test",
+			rest:    []string{"--keep_going"},
+		},
+		"no command": {
+			args: []string{"--host_jvm_args=-Xmx1g"},
+			want: BazelFlagValues{"host_jvm_args": []string{"-Xmx1g"}},
+		},
+		"missing value": {
+			args:    []string{"--output_base"},
+			wantErr: "failed to parse bazel startup options: value-requiring flag output_base didn't have value",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := ParseCommandLineStartupArgs(flagData, tc.args)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, (&StartupOptions{Options: parsed.Options}).Values())
+			require.Equal(t, tc.command, parsed.Command)
+			require.Equal(t, tc.rest, parsed.ArgsAfterCommand)
+		})
+	}
+}
+
+func TestMergeStartupOptions(t *testing.T) {
+	flagData := startupTestFlagData(t)
+	parser := NewBazelRcParser("", flagData)
+	systemRc, err := parser.Parsefile(strings.NewReader("startup --output_user_root=/system --host_jvm_args=-Dsystem"), "/etc/bazel.bazelrc")
+	require.NoError(t, err)
+	workspaceRc, err := parser.Parsefile(strings.NewReader("startup --output_user_root=/workspace\nbuild --keep_going"), "/workspace/.bazelrc")
+	require.NoError(t, err)
+
+	commandLine, err := ParseCommandLineStartupArgs(flagData, []string{"--host_jvm_args=-Dcli", "build"})
+	require.NoError(t, err)
+	merged := MergeStartupOptions(commandLine.Options, systemRc, workspaceRc)
+	require.Equal(t, "/workspace", merged.OutputUserRoot())
+	require.Equal(t, []string{"-Dsystem", "-Dcli"}, merged.HostJvmArgs())
+	require.Equal(t, "/etc/bazel.bazelrc", merged.Options[0].Span().Start.File)
+	require.Equal(t, 0, merged.Options[3].Span().Start.ArgIndex)
+
+	commandLine, err = ParseCommandLineStartupArgs(flagData, []string{"--ignore_all_rc_files", "--output_user_root=/cli", "build"})
+	require.NoError(t, err)
+	merged = MergeStartupOptions(commandLine.Options, systemRc, workspaceRc)
+	require.Equal(t, BazelFlagValues{
+		"ignore_all_rc_files": []string{"true"},
+		"output_user_root":    []string{"/cli"},
+	}, merged.Values())
+}
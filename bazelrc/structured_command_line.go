@@ -0,0 +1,205 @@
+package bazelrc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/bazel-contrib/bazelrc-parser-go/bazel_protos/command_line"
+	"github.com/bazel-contrib/bazelrc-parser-go/bazel_protos/option_filters"
+)
+
+// StructuredCommandLine holds the command lines Bazel reports in the Build Event Protocol for an invocation.
+type StructuredCommandLine struct {
+	// Original is the command line labelled "original": the options as they were set in bazelrc files and on the command line, before `--config` and expansion flags were expanded.
+	Original *command_line.CommandLine
+	// Canonical is the command line labelled "canonical": the effective options, with `--config` and expansion flags expanded, and overridden values removed.
+	Canonical *command_line.CommandLine
+	// Sources maps each option in Original and Canonical to the option it came from, as command_line.Option has no field for its source.
+	Sources map[*command_line.Option]ResolvedOption
+}
+
+// BuildStructuredCommandLine computes the command lines Bazel would report for an invocation with the arguments argv (including the Bazel binary, e.g. `["bazel", "--batch", "build", "//foo"]`), without invoking bazel.
+// contents holds the bazelrc files Bazel would read, and resolverOptions configure how options are resolved.
+// Argument indexes in the positions of options from the command line are indexes into argv.
+func BuildStructuredCommandLine(contents *BazelrcContents, knownFlagData *FlagData, argv []string, resolverOptions ...ResolverOption) (*StructuredCommandLine, error) {
+	if len(argv) == 0 {
+		return nil, errors.New("failed to build structured command line: no arguments")
+	}
+	startupArgs, err := ParseCommandLineStartupArgs(knownFlagData, argv[1:])
+	if err != nil {
+		return nil, err
+	}
+	offsetArgIndexes(startupArgs.Options, 1)
+	if startupArgs.Command == "" {
+		return nil, errors.New("failed to build structured command line: no command")
+	}
+	commandArgs, err := ParseCommandLineArgsAfterCommand(knownFlagData, startupArgs.ArgsAfterCommand)
+	if err != nil {
+		return nil, err
+	}
+	offsetArgIndexes(commandArgs.Options, len(argv)-len(startupArgs.ArgsAfterCommand))
+	resolver := NewResolver(contents, knownFlagData, resolverOptions...)
+	resolved, err := resolver.Resolve(startupArgs.Command, commandArgs.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	startupFlagData := knownFlagData
+	if knownFlagData != nil && knownFlagData.StartupOptions != nil {
+		startupFlagData = knownFlagData.StartupOptions
+	}
+	startupOptions := asResolvedOptions(MergeStartupOptions(startupArgs.Options, contents).Options)
+	residue := append(append([]string(nil), commandArgs.Targets...), commandArgs.ExecutableArgs...)
+
+	var explicitOptions []ResolvedOption
+	for _, rcCommand := range CommandChain(startupArgs.Command) {
+		for _, option := range resolver.optionsByCommand[rcCommand] {
+			if knownFlagData.AppliesToCommand(option.Name, startupArgs.Command) {
+				explicitOptions = append(explicitOptions, ResolvedOption{Option: option})
+			}
+		}
+	}
+	explicitOptions = append(explicitOptions, asResolvedOptions(commandArgs.Options)...)
+
+	builder := &structuredCommandLineBuilder{sources: make(map[*command_line.Option]ResolvedOption)}
+	original := &command_line.CommandLine{
+		CommandLineLabel: "original",
+		Sections: []*command_line.CommandLineSection{
+			chunkSection("executable", "bazel"),
+			builder.optionSection("startup options", startupFlagData, startupOptions, originalForm),
+			chunkSection("command", startupArgs.Command),
+			builder.optionSection("command options", knownFlagData, explicitOptions, originalForm),
+			chunkSection("residue", residue...),
+		},
+	}
+
+	canonicalStartupOptions := canonicalizeOptions(startupFlagData, startupOptions)
+	canonicalStartupOptions = slices.DeleteFunc(canonicalStartupOptions, func(option ResolvedOption) bool { return option.Name == "ignore_all_rc_files" })
+	canonicalStartupSection := builder.optionSection("startup options", startupFlagData, canonicalStartupOptions, canonicalForm)
+	// The canonical command line already includes the options from bazelrc files, so mustn't read them again.
+	// `--ignore_all_rc_files` is a boolean startup option whatever startupFlagData says.
+	ignoreAllRcFiles := Option{Name: "ignore_all_rc_files", Value: "true"}
+	canonicalStartupSection.GetOptionList().Option = append(canonicalStartupSection.GetOptionList().Option, &command_line.Option{
+		CombinedForm: "--ignore_all_rc_files",
+		OptionName:   ignoreAllRcFiles.Name,
+		OptionValue:  canonicalValue(&FlagData{ValueTypes: map[string]ValueType{ignoreAllRcFiles.Name: ValueTypeBoolean}}, ignoreAllRcFiles),
+		EffectTags:   []option_filters.OptionEffectTag{option_filters.OptionEffectTag_CHANGES_INPUTS},
+	})
+	canonical := &command_line.CommandLine{
+		CommandLineLabel: "canonical",
+		Sections: []*command_line.CommandLineSection{
+			chunkSection("executable", "bazel"),
+			canonicalStartupSection,
+			chunkSection("command", startupArgs.Command),
+			builder.optionSection("command options", knownFlagData, canonicalizeOptions(knownFlagData, resolved.Options), canonicalForm),
+			chunkSection("residue", residue...),
+		},
+	}
+	return &StructuredCommandLine{Original: original, Canonical: canonical, Sources: builder.sources}, nil
+}
+
+// StructuredCommandLineJSON returns the JSON form of commandLine, as it appears in Bazel's JSON build event files.
+func StructuredCommandLineJSON(commandLine *command_line.CommandLine) ([]byte, error) {
+	return protojson.Marshal(commandLine)
+}
+
+// structuredCommandLineBuilder records the source of each option added to a command line.
+type structuredCommandLineBuilder struct {
+	sources map[*command_line.Option]ResolvedOption
+}
+
+// optionSection makes a section listing options, described using knownFlagData, with each option's combined form and value given by form.
+func (b *structuredCommandLineBuilder) optionSection(label string, knownFlagData *FlagData, options []ResolvedOption, form func(*FlagData, Option) (string, string)) *command_line.CommandLineSection {
+	optionList := &command_line.OptionList{}
+	for _, option := range options {
+		combinedForm, value := form(knownFlagData, option.Option)
+		protoOption := &command_line.Option{
+			CombinedForm: combinedForm,
+			OptionName:   option.Name,
+			OptionValue:  value,
+		}
+		if knownFlagData != nil {
+			for _, tag := range knownFlagData.EffectTags[option.Name] {
+				if value, ok := option_filters.OptionEffectTag_value[strings.ToUpper(tag)]; ok {
+					protoOption.EffectTags = append(protoOption.EffectTags, option_filters.OptionEffectTag(value))
+				}
+			}
+			for _, tag := range knownFlagData.MetadataTags[option.Name] {
+				if value, ok := option_filters.OptionMetadataTag_value[strings.ToUpper(tag)]; ok {
+					protoOption.MetadataTags = append(protoOption.MetadataTags, option_filters.OptionMetadataTag(value))
+				}
+			}
+		}
+		b.sources[protoOption] = option
+		optionList.Option = append(optionList.Option, protoOption)
+	}
+	return &command_line.CommandLineSection{
+		SectionLabel: label,
+		SectionType:  &command_line.CommandLineSection_OptionList{OptionList: optionList},
+	}
+}
+
+// chunkSection makes a section listing non-options, e.g. the command or targets.
+func chunkSection(label string, chunks ...string) *command_line.CommandLineSection {
+	return &command_line.CommandLineSection{
+		SectionLabel: label,
+		SectionType:  &command_line.CommandLineSection_ChunkList{ChunkList: &command_line.ChunkList{Chunk: chunks}},
+	}
+}
+
+// originalForm describes option as it was written, e.g. `--jobs 10` or `--nokeep_going`, and returns its parsed value.
+func originalForm(_ *FlagData, option Option) (string, string) {
+	return strings.Join(tokenValues(option.Tokens), " "), option.Value
+}
+
+// canonicalForm describes option in canonical form, e.g. `--keep_going=0`, and returns its value in canonical form.
+func canonicalForm(knownFlagData *FlagData, option Option) (string, string) {
+	value := canonicalValue(knownFlagData, option)
+	return fmt.Sprintf("--%s=%s", option.Name, value), value
+}
+
+// canonicalizeOptions removes options which are overridden by later options for the same flag, unless the flag accumulates values.
+func canonicalizeOptions(knownFlagData *FlagData, options []ResolvedOption) []ResolvedOption {
+	lastIndexes := make(map[string]int)
+	for i, option := range options {
+		lastIndexes[option.Name] = i
+	}
+	var canonical []ResolvedOption
+	for i, option := range options {
+		if lastIndexes[option.Name] == i || (knownFlagData != nil && knownFlagData.AllowsMultiple[option.Name]) {
+			canonical = append(canonical, option)
+		}
+	}
+	return canonical
+}
+
+func asResolvedOptions(options []Option) []ResolvedOption {
+	resolved := make([]ResolvedOption, 0, len(options))
+	for _, option := range options {
+		resolved = append(resolved, ResolvedOption{Option: option})
+	}
+	return resolved
+}
+
+// offsetArgIndexes adds offset to the argument index of each command-line position in options, e.g. to make them relative to a larger argument list.
+func offsetArgIndexes(options []Option, offset int) {
+	offsetSpan := func(span *Span) {
+		if !span.IsZero() && span.Start.IsCommandLine() {
+			span.Start.ArgIndex += offset
+			span.End.ArgIndex += offset
+		}
+	}
+	for i := range options {
+		tokens := append([]Token(nil), options[i].Tokens...)
+		for j := range tokens {
+			offsetSpan(&tokens[j].Span)
+		}
+		options[i].Tokens = tokens
+		offsetSpan(&options[i].NameSpan)
+		offsetSpan(&options[i].ValueSpan)
+	}
+}
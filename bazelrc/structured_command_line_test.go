@@ -0,0 +1,130 @@
+package bazelrc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bazel-contrib/bazelrc-parser-go/bazel_protos/command_line"
+	"github.com/bazel-contrib/bazelrc-parser-go/bazel_protos/option_filters"
+)
+
+func TestBuildStructuredCommandLine(t *testing.T) {
+	flagData := &FlagData{
+		BooleanFlags:   map[string]bool{"keep_going": true},
+		AllowsMultiple: map[string]bool{"copt": true},
+		EffectTags:     map[string][]string{"jobs": {"host_machine_resource_optimizations", "execution"}},
+		MetadataTags:   map[string][]string{"copt": {"experimental"}},
+		StartupOptions: &FlagData{
+			BooleanFlags: map[string]bool{"batch": true, "ignore_all_rc_files": true},
+		},
+	}
+	contents, err := NewBazelRcParser("", flagData).Parsefile(strings.NewReader(`startup --output_base=/tmp/out
+build --jobs 10 --copt=-O1
+build:ci --keep_going --jobs=20
+test --test_output=errors
+`), "/sample/bazelrc")
+	require.NoError(t, err)
+
+	commandLine, err := BuildStructuredCommandLine(contents, flagData, []string{"/usr/bin/bazel", "--batch", "build", "--config=ci", "//foo", "--copt=-O2", "--", "arg"})
+	require.NoError(t, err)
+
+	option := func(combinedForm, name, value string) *command_line.Option {
+		return &command_line.Option{CombinedForm: combinedForm, OptionName: name, OptionValue: value}
+	}
+	optionSection := func(label string, options ...*command_line.Option) *command_line.CommandLineSection {
+		return &command_line.CommandLineSection{SectionLabel: label, SectionType: &command_line.CommandLineSection_OptionList{OptionList: &command_line.OptionList{Option: options}}}
+	}
+	jobsTags := []option_filters.OptionEffectTag{option_filters.OptionEffectTag_HOST_MACHINE_RESOURCE_OPTIMIZATIONS, option_filters.OptionEffectTag_EXECUTION}
+	withTags := func(option *command_line.Option, effectTags []option_filters.OptionEffectTag, metadataTags []option_filters.OptionMetadataTag) *command_line.Option {
+		option.EffectTags = effectTags
+		option.MetadataTags = metadataTags
+		return option
+	}
+	experimental := []option_filters.OptionMetadataTag{option_filters.OptionMetadataTag_EXPERIMENTAL}
+
+	wantOriginal := &command_line.CommandLine{
+		CommandLineLabel: "original",
+		Sections: []*command_line.CommandLineSection{
+			chunkSection("executable", "bazel"),
+			optionSection("startup options",
+				option("--output_base=/tmp/out", "output_base", "/tmp/out"),
+				option("--batch", "batch", "true"),
+			),
+			chunkSection("command", "build"),
+			optionSection("command options",
+				withTags(option("--jobs 10", "jobs", "10"), jobsTags, nil),
+				withTags(option("--copt=-O1", "copt", "-O1"), nil, experimental),
+				option("--config=ci", "config", "ci"),
+				withTags(option("--copt=-O2", "copt", "-O2"), nil, experimental),
+			),
+			chunkSection("residue", "//foo", "arg"),
+		},
+	}
+	require.True(t, proto.Equal(wantOriginal, commandLine.Original), "got %v", commandLine.Original)
+
+	wantCanonical := &command_line.CommandLine{
+		CommandLineLabel: "canonical",
+		Sections: []*command_line.CommandLineSection{
+			chunkSection("executable", "bazel"),
+			optionSection("startup options",
+				option("--output_base=/tmp/out", "output_base", "/tmp/out"),
+				option("--batch=1", "batch", "1"),
+				withTags(option("--ignore_all_rc_files", "ignore_all_rc_files", "1"), []option_filters.OptionEffectTag{option_filters.OptionEffectTag_CHANGES_INPUTS}, nil),
+			),
+			chunkSection("command", "build"),
+			optionSection("command options",
+				withTags(option("--copt=-O1", "copt", "-O1"), nil, experimental),
+				option("--keep_going=1", "keep_going", "1"),
+				withTags(option("--jobs=20", "jobs", "20"), jobsTags, nil),
+				withTags(option("--copt=-O2", "copt", "-O2"), nil, experimental),
+			),
+			chunkSection("residue", "//foo", "arg"),
+		},
+	}
+	require.True(t, proto.Equal(wantCanonical, commandLine.Canonical), "got %v", commandLine.Canonical)
+
+	canonicalOptions := commandLine.Canonical.Sections[3].GetOptionList().Option
+	jobsSource := commandLine.Sources[canonicalOptions[2]]
+	require.Equal(t, 3, jobsSource.Span().Start.Line)
+	require.Equal(t, []string{"ci"}, []string{jobsSource.Via[0].Value})
+	require.Equal(t, 3, jobsSource.Via[0].Span().Start.ArgIndex)
+	require.Equal(t, 5, commandLine.Sources[canonicalOptions[3]].Span().Start.ArgIndex)
+	require.Equal(t, 1, commandLine.Sources[commandLine.Original.Sections[1].GetOptionList().Option[1]].Span().Start.ArgIndex)
+
+	encoded, err := StructuredCommandLineJSON(commandLine.Canonical)
+	require.NoError(t, err)
+	require.Contains(t, strings.ReplaceAll(string(encoded), " ", ""), `"commandLineLabel":"canonical"`)
+	var decoded command_line.CommandLine
+	require.NoError(t, protojson.Unmarshal(encoded, &decoded))
+	require.True(t, proto.Equal(commandLine.Canonical, &decoded))
+}
+
+func TestBuildStructuredCommandLineErrors(t *testing.T) {
+	contents, err := NewBazelRcParser("", &FlagData{}).Parsefile(strings.NewReader("build --jobs=10"), "/sample/bazelrc")
+	require.NoError(t, err)
+	for name, tc := range map[string]struct {
+		argv    []string
+		wantErr string
+	}{
+		"no arguments": {
+			wantErr: "failed to build structured command line: no arguments",
+		},
+		"no command": {
+			argv:    []string{"bazel", "--batch=true"},
+			wantErr: "failed to build structured command line: no command",
+		},
+		"undefined config": {
+			argv:    []string{"bazel", "build", "--config=missing"},
+			wantErr: "config value 'missing' is not defined in any .rc file",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := BuildStructuredCommandLine(contents, &FlagData{}, tc.argv)
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
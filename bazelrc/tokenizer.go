@@ -0,0 +1,188 @@
+package bazelrc
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Token is a single word from a bazelrc line or command line, after quotes and escapes have been processed.
+type Token struct {
+	// Value is the text of the token, with quotes and escapes processed.
+	Value string
+	// Span covers the source text of the token, including any quotes or escape characters.
+	Span Span
+	// sourceOffsets maps each byte index of Value to the offset (relative to Span.Start) of the source byte it came from.
+	// These differ when quotes or escape characters precede the byte.
+	sourceOffsets []int
+}
+
+// newArgToken makes a token for a command-line argument, which needs no unquoting.
+func newArgToken(arg string, argIndex int) Token {
+	sourceOffsets := make([]int, len(arg))
+	for i := range sourceOffsets {
+		sourceOffsets[i] = i
+	}
+	start := Position{Column: 1, ArgIndex: argIndex}
+	return Token{
+		Value:         arg,
+		Span:          Span{Start: start, End: start.advance(len(arg))},
+		sourceOffsets: sourceOffsets,
+	}
+}
+
+// spanBefore returns the span of the source text which produced Value[:i].
+func (t Token) spanBefore(i int) Span {
+	if i >= len(t.Value) {
+		return t.Span
+	}
+	return Span{Start: t.Span.Start, End: t.Span.Start.advance(t.sourceOffsets[i])}
+}
+
+// spanFrom returns the span of the source text which produced Value[i:].
+func (t Token) spanFrom(i int) Span {
+	if i >= len(t.Value) {
+		return Span{Start: t.Span.End, End: t.Span.End}
+	}
+	return Span{Start: t.Span.Start.advance(t.sourceOffsets[i]), End: t.Span.End}
+}
+
+// tokenizeError is returned by tokenize when a line can't be split into tokens.
+type tokenizeError struct {
+	// span covers the token which couldn't be completed.
+	span    Span
+	message string
+}
+
+func (e *tokenizeError) Error() string {
+	return e.message
+}
+
+type lexerState int
+
+const (
+	startState           lexerState = iota // no runes have been seen
+	inWordState                            // processing regular runes in a word
+	escapingState                          // we have just consumed an escape rune; the next rune is literal
+	escapingQuotedState                    // we have just consumed an escape rune within a quoted string
+	quotingEscapingState                   // we are within a quoted string that supports escaping ("...")
+	quotingState                           // we are within a string that does not support escaping ('...')
+	commentState                           // we are within a comment (everything following an unquoted or unescaped #)
+)
+
+// tokenize splits a line into tokens using shell-like quoting rules, recording where in the source each token came from.
+// The rules match those of github.com/google/shlex, which earlier versions of this package used: double quotes support backslash escapes,
+// single quotes don't, and a # at the start of a token begins a comment which runs to the end of the line.
+// start is the position of the first byte of line.
+func tokenize(line string, start Position) ([]Token, error) {
+	tokens := make([]Token, 0)
+	state := startState
+	var value []byte
+	var sourceOffsets []int
+	tokenStart := 0
+
+	appendRune := func(r rune, offset int) {
+		for i := 0; i < utf8.RuneLen(r); i++ {
+			sourceOffsets = append(sourceOffsets, offset-tokenStart+i)
+		}
+		value = utf8.AppendRune(value, r)
+	}
+	finishToken := func(end int) {
+		tokens = append(tokens, Token{
+			Value:         string(value),
+			Span:          Span{Start: start.advance(tokenStart), End: start.advance(end)},
+			sourceOffsets: sourceOffsets,
+		})
+		value = nil
+		sourceOffsets = nil
+	}
+	fail := func(message string) error {
+		return &tokenizeError{
+			span:    Span{Start: start.advance(tokenStart), End: start.advance(len(line))},
+			message: message,
+		}
+	}
+
+	for offset := 0; offset <= len(line); {
+		r, width := utf8.DecodeRuneInString(line[offset:])
+		atEOF := offset == len(line)
+
+		switch state {
+		case startState:
+			switch {
+			case atEOF:
+			case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+			case r == '"':
+				tokenStart = offset
+				state = quotingEscapingState
+			case r == '\'':
+				tokenStart = offset
+				state = quotingState
+			case r == '\\':
+				tokenStart = offset
+				state = escapingState
+			case r == '#':
+				state = commentState
+			default:
+				tokenStart = offset
+				appendRune(r, offset)
+				state = inWordState
+			}
+		case inWordState:
+			switch {
+			case atEOF || r == ' ' || r == '\t' || r == '\r' || r == '\n':
+				finishToken(offset)
+				state = startState
+			case r == '"':
+				state = quotingEscapingState
+			case r == '\'':
+				state = quotingState
+			case r == '\\':
+				state = escapingState
+			default:
+				appendRune(r, offset)
+			}
+		case escapingState, escapingQuotedState:
+			if atEOF {
+				return nil, fail("EOF found after escape character")
+			}
+			appendRune(r, offset)
+			if state == escapingState {
+				state = inWordState
+			} else {
+				state = quotingEscapingState
+			}
+		case quotingEscapingState:
+			switch {
+			case atEOF:
+				return nil, fail("EOF found when expecting closing quote")
+			case r == '"':
+				state = inWordState
+			case r == '\\':
+				state = escapingQuotedState
+			default:
+				appendRune(r, offset)
+			}
+		case quotingState:
+			switch {
+			case atEOF:
+				return nil, fail("EOF found when expecting closing quote")
+			case r == '\'':
+				state = inWordState
+			default:
+				appendRune(r, offset)
+			}
+		case commentState:
+			if r == '\n' {
+				state = startState
+			}
+		default:
+			return nil, fmt.Errorf("unexpected lexer state: %v", state)
+		}
+
+		if atEOF {
+			break
+		}
+		offset += width
+	}
+	return tokens, nil
+}
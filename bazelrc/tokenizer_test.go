@@ -0,0 +1,99 @@
+package bazelrc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	for name, tc := range map[string]struct {
+		line          string
+		want          []string
+		wantSpans     [][2]int
+		expectedError string
+	}{
+		"empty": {
+			line: "",
+			want: []string{},
+		},
+		"whitespace separated": {
+			line:      "build  --foo=bar\t-j 1",
+			want:      []string{"build", "--foo=bar", "-j", "1"},
+			wantSpans: [][2]int{{0, 5}, {7, 16}, {17, 19}, {20, 21}},
+		},
+		"double quotes": {
+			line:      `--copt="--foo --bar" x`,
+			want:      []string{"--copt=--foo --bar", "x"},
+			wantSpans: [][2]int{{0, 20}, {21, 22}},
+		},
+		"single quotes don't escape": {
+			line: `'a\b' "a\"b"`,
+			want: []string{`a\b`, `a"b`},
+		},
+		"escaped space": {
+			line: `a\ b c`,
+			want: []string{"a b", "c"},
+		},
+		"empty quotes": {
+			line: `a "" b`,
+			want: []string{"a", "", "b"},
+		},
+		"comment at start of token": {
+			line: "a #b c",
+			want: []string{"a"},
+		},
+		"hash inside token": {
+			line: "a#b c",
+			want: []string{"a#b", "c"},
+		},
+		"multi-byte characters": {
+			line:      "é ü",
+			want:      []string{"é", "ü"},
+			wantSpans: [][2]int{{0, 2}, {3, 5}},
+		},
+		"unterminated double quote": {
+			line:          `a "b`,
+			expectedError: "EOF found when expecting closing quote",
+		},
+		"unterminated single quote": {
+			line:          `a 'b`,
+			expectedError: "EOF found when expecting closing quote",
+		},
+		"trailing escape": {
+			line:          `a \`,
+			expectedError: "EOF found after escape character",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tokens, err := tokenize(tc.line, Position{Line: 1, Column: 1, ArgIndex: -1})
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, tokenValues(tokens))
+			if tc.wantSpans != nil {
+				var gotSpans [][2]int
+				for _, token := range tokens {
+					gotSpans = append(gotSpans, [2]int{token.Span.Start.Offset, token.Span.End.Offset})
+				}
+				require.Equal(t, tc.wantSpans, gotSpans)
+			}
+		})
+	}
+}
+
+func TestTokenSubSpans(t *testing.T) {
+	tokens, err := tokenize(`  --foo="a b"`, Position{Line: 3, Column: 1, Offset: 100, ArgIndex: -1})
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	token := tokens[0]
+	require.Equal(t, "--foo=a b", token.Value)
+
+	require.Equal(t, Position{Line: 3, Column: 3, Offset: 102, ArgIndex: -1}, token.spanBefore(5).Start)
+	require.Equal(t, Position{Line: 3, Column: 8, Offset: 107, ArgIndex: -1}, token.spanBefore(5).End)
+	// The value starts at the opening quote's contents, and ends after the closing quote.
+	require.Equal(t, Position{Line: 3, Column: 10, Offset: 109, ArgIndex: -1}, token.spanFrom(6).Start)
+	require.Equal(t, Position{Line: 3, Column: 14, Offset: 113, ArgIndex: -1}, token.spanFrom(6).End)
+}
@@ -0,0 +1,249 @@
+package bazelrc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValueType describes what values a flag accepts, and how they're spelled canonically.
+type ValueType string
+
+const (
+	// ValueTypeString accepts any value, which is left unchanged.
+	ValueTypeString ValueType = "string"
+	// ValueTypeBoolean accepts true/false, yes/no, 1/0 and t/f or y/n in any case; canonically "true" or "false".
+	ValueTypeBoolean ValueType = "boolean"
+	// ValueTypeTriState accepts "auto" and any boolean spelling; canonically "auto", "yes" or "no".
+	ValueTypeTriState ValueType = "tri-state"
+	// ValueTypeInteger accepts a decimal integer.
+	ValueTypeInteger ValueType = "integer"
+	// ValueTypeResourceCount accepts a number, or "auto", "HOST_CPUS" or "HOST_RAM" optionally followed by `-` or `*` and a number (e.g. `HOST_CPUS*.5`).
+	ValueTypeResourceCount ValueType = "resource count"
+	// ValueTypeDuration accepts a sequence of integers with units of d, h, m, s or ms (e.g. `1h30m`).
+	ValueTypeDuration ValueType = "duration"
+	// ValueTypeEnum accepts one of the values listed in FlagData.EnumValues, in any case.
+	ValueTypeEnum ValueType = "enum"
+	// ValueTypeLabel accepts a Bazel label, which is normalized by NormalizeLabel.
+	ValueTypeLabel ValueType = "label"
+	// ValueTypeList accepts a comma-separated list of strings.
+	ValueTypeList ValueType = "list"
+)
+
+// valueTypesOfBuildSettings gives the ValueType of values for each type of Starlark build setting.
+var valueTypesOfBuildSettings = map[BuildSettingType]ValueType{
+	BuildSettingBool:       ValueTypeBoolean,
+	BuildSettingInt:        ValueTypeInteger,
+	BuildSettingString:     ValueTypeString,
+	BuildSettingStringList: ValueTypeList,
+	BuildSettingLabel:      ValueTypeLabel,
+}
+
+// InvalidFlagValueError is returned when a flag's value can't be converted to the flag's ValueType.
+type InvalidFlagValueError struct {
+	// Location is the flag's value, or the whole flag if its value was implied.
+	Location
+	// Flag is the name of the flag, without leading dashes.
+	Flag string
+	// Value is the value as written.
+	Value string
+	// Type is the flag's ValueType.
+	Type ValueType
+	Err  error
+}
+
+func (e *InvalidFlagValueError) Error() string {
+	return e.describeSelf(fmt.Sprintf("invalid value %q for %s flag %s: %v", e.Value, e.Type, e.Flag, e.Err))
+}
+
+func (e *InvalidFlagValueError) Unwrap() error {
+	return e.Err
+}
+
+// ValueType returns the ValueType of the named flag (without leading dashes), which may be a Starlark flag.
+// Flags without a known type are treated as strings, including those in BooleanFlags but not ValueTypes, as flags which may be given without a value aren't necessarily booleans
+// (e.g. `--subcommands=pretty_print` or `--cache_test_results=auto`).
+func (d *FlagData) ValueType(flagName string) ValueType {
+	if d == nil {
+		return ValueTypeString
+	}
+	if IsStarlarkFlag(flagName) {
+		if settingType, ok := d.buildSettingType(flagName); ok {
+			if valueType, ok := valueTypesOfBuildSettings[settingType]; ok {
+				return valueType
+			}
+		}
+		return ValueTypeString
+	}
+	if valueType, ok := d.ValueTypes[flagName]; ok {
+		return valueType
+	}
+	return ValueTypeString
+}
+
+// NormalizeValue converts value to the canonical spelling for the named flag's ValueType (e.g. `yes` to `true` for a boolean flag).
+// It returns an error if value isn't valid for the flag.
+func (d *FlagData) NormalizeValue(flagName string, value string) (string, error) {
+	switch d.ValueType(flagName) {
+	case ValueTypeBoolean:
+		return normalizeBoolean(value)
+	case ValueTypeTriState:
+		if strings.EqualFold(value, "auto") {
+			return "auto", nil
+		}
+		boolean, err := normalizeBoolean(value)
+		if err != nil {
+			return "", errors.New("expected auto or a boolean")
+		}
+		if boolean == "true" {
+			return "yes", nil
+		}
+		return "no", nil
+	case ValueTypeInteger:
+		integer, err := strconv.Atoi(value)
+		if err != nil {
+			return "", errors.New("expected an integer")
+		}
+		return strconv.Itoa(integer), nil
+	case ValueTypeResourceCount:
+		return normalizeResourceCount(value)
+	case ValueTypeDuration:
+		return normalizeDuration(value)
+	case ValueTypeEnum:
+		allowed := d.EnumValues[flagName]
+		for _, allowedValue := range allowed {
+			if strings.EqualFold(value, allowedValue) {
+				return allowedValue, nil
+			}
+		}
+		return "", fmt.Errorf("expected one of %s", strings.Join(allowed, ", "))
+	case ValueTypeLabel:
+		if IsStarlarkFlag(value) {
+			return NormalizeLabel(value), nil
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+// isUntypedBooleanFlag returns whether the named flag may be given without a value according to BooleanFlags, but its type isn't known from ValueTypes.
+// Such flags are usually booleans, but may also be e.g. tri-states, so their values aren't validated.
+func (d *FlagData) isUntypedBooleanFlag(flagName string) bool {
+	if d == nil || !d.BooleanFlags[flagName] {
+		return false
+	}
+	_, typed := d.ValueTypes[flagName]
+	return !typed
+}
+
+// comparableValue normalizes value as NormalizeValue does, for comparing values of the named flag, returning it unchanged if it isn't valid.
+// Values of flags for which isUntypedBooleanFlag is true are normalized if they're spelled as booleans (e.g. `1` as `true`), and otherwise left as written (e.g. `pretty_print`).
+func (d *FlagData) comparableValue(flagName string, value string) string {
+	if d.isUntypedBooleanFlag(flagName) {
+		if boolean, err := normalizeBoolean(value); err == nil {
+			return boolean
+		}
+		return value
+	}
+	if normalized, err := d.NormalizeValue(flagName, value); err == nil {
+		return normalized
+	}
+	return value
+}
+
+// acceptsNoValue returns whether the named flag may be given without a value (e.g. `--subcommands` or `--nosubcommands`).
+// Expansion flags never take a value.
+func (d *FlagData) acceptsNoValue(flagName string) bool {
+	if d.BooleanFlags[flagName] {
+		return true
+	}
+	if _, isExpansion := d.expansion(flagName); isExpansion {
+		return true
+	}
+	valueType := d.ValueTypes[flagName]
+	return valueType == ValueTypeBoolean || valueType == ValueTypeTriState
+}
+
+// isExplicitValueForFlagAcceptingNoValue returns whether a token following a flag which may be given without a value (e.g. `--subcommands true`) should be treated as the flag's value, rather than as the next argument.
+func (d *FlagData) isExplicitValueForFlagAcceptingNoValue(flagName string, token string) bool {
+	if _, err := normalizeBoolean(token); err == nil {
+		return true
+	}
+	return d.ValueType(flagName) == ValueTypeTriState && strings.EqualFold(token, "auto")
+}
+
+func normalizeBoolean(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "true", "t", "yes", "y", "1":
+		return "true", nil
+	case "false", "f", "no", "n", "0":
+		return "false", nil
+	}
+	return "", errors.New("expected a boolean")
+}
+
+var resourceCountPattern = regexp.MustCompile(`^(auto|HOST_CPUS|HOST_RAM)(?:([-*])([0-9]*\.?[0-9]+))?$`)
+
+func normalizeResourceCount(value string) (string, error) {
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	}
+	match := resourceCountPattern.FindStringSubmatch(value)
+	if match == nil {
+		return "", errors.New("expected a number, or auto, HOST_CPUS or HOST_RAM optionally followed by [-|*]<number>")
+	}
+	if match[2] == "" {
+		return match[1], nil
+	}
+	number, err := strconv.ParseFloat(match[3], 64)
+	if err != nil {
+		return "", err
+	}
+	return match[1] + match[2] + strconv.FormatFloat(number, 'f', -1, 64), nil
+}
+
+var (
+	durationPattern          = regexp.MustCompile(`^(?:[0-9]+(?:d|h|ms|m|s))+$`)
+	durationComponentPattern = regexp.MustCompile(`([0-9]+)(d|h|ms|m|s)`)
+)
+
+func normalizeDuration(value string) (string, error) {
+	if !durationPattern.MatchString(value) {
+		return "", errors.New("expected a duration like 30s or 1h30m")
+	}
+	var normalized strings.Builder
+	for _, component := range durationComponentPattern.FindAllStringSubmatch(value, -1) {
+		number, err := strconv.Atoi(component[1])
+		if err != nil {
+			return "", err
+		}
+		normalized.WriteString(strconv.Itoa(number))
+		normalized.WriteString(component[2])
+	}
+	return normalized.String(), nil
+}
+
+// normalizeOptionValues converts the value of each option to its canonical spelling, returning an InvalidFlagValueError for the first invalid value.
+func (p *BazelRcParser) normalizeOptionValues(options []Option, importCallStack []string) error {
+	for i := range options {
+		normalized, err := p.knownFlagData.NormalizeValue(options[i].Name, options[i].Value)
+		if err != nil {
+			span := options[i].ValueSpan
+			if span.IsZero() {
+				span = options[i].Span()
+			}
+			return &InvalidFlagValueError{
+				Location: newSpanLocation(importCallStack, span),
+				Flag:     options[i].Name,
+				Value:    options[i].Value,
+				Type:     p.knownFlagData.ValueType(options[i].Name),
+				Err:      err,
+			}
+		}
+		options[i].Value = normalized
+	}
+	return nil
+}
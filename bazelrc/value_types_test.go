@@ -0,0 +1,194 @@
+package bazelrc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var valueTypesFlagData = &FlagData{
+	BooleanFlags: map[string]bool{
+		"bool_flag":      true,
+		"tristate_flag":  true,
+		"jobs":           false,
+		"local_cpus":     false,
+		"timeout":        false,
+		"mode":           false,
+		"toolchain":      false,
+		"tag_filters":    false,
+		"untyped_string": false,
+	},
+	ValueTypes: map[string]ValueType{
+		"bool_flag":     ValueTypeBoolean,
+		"tristate_flag": ValueTypeTriState,
+		"jobs":          ValueTypeInteger,
+		"local_cpus":    ValueTypeResourceCount,
+		"timeout":       ValueTypeDuration,
+		"mode":          ValueTypeEnum,
+		"toolchain":     ValueTypeLabel,
+		"tag_filters":   ValueTypeList,
+	},
+	EnumValues: map[string][]string{
+		"mode": {"fastbuild", "dbg", "opt"},
+	},
+	BuildSettings: map[string]BuildSettingType{
+		"//pkg:level": BuildSettingInt,
+	},
+}
+
+func TestNormalizeValue(t *testing.T) {
+	for name, tc := range map[string]struct {
+		flag    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		"boolean true":             {flag: "bool_flag", value: "true", want: "true"},
+		"boolean yes":              {flag: "bool_flag", value: "Yes", want: "true"},
+		"boolean 1":                {flag: "bool_flag", value: "1", want: "true"},
+		"boolean no":               {flag: "bool_flag", value: "no", want: "false"},
+		"boolean 0":                {flag: "bool_flag", value: "0", want: "false"},
+		"boolean invalid":          {flag: "bool_flag", value: "maybe", wantErr: true},
+		"tri-state auto":           {flag: "tristate_flag", value: "AUTO", want: "auto"},
+		"tri-state true":           {flag: "tristate_flag", value: "true", want: "yes"},
+		"tri-state 0":              {flag: "tristate_flag", value: "0", want: "no"},
+		"tri-state invalid":        {flag: "tristate_flag", value: "sometimes", wantErr: true},
+		"integer":                  {flag: "jobs", value: "010", want: "10"},
+		"negative integer":         {flag: "jobs", value: "-2", want: "-2"},
+		"integer invalid":          {flag: "jobs", value: "ten", wantErr: true},
+		"resource count number":    {flag: "local_cpus", value: "4.0", want: "4"},
+		"resource count keyword":   {flag: "local_cpus", value: "HOST_CPUS", want: "HOST_CPUS"},
+		"resource count operation": {flag: "local_cpus", value: "HOST_CPUS*.5", want: "HOST_CPUS*0.5"},
+		"resource count auto":      {flag: "local_cpus", value: "auto-1", want: "auto-1"},
+		"resource count invalid":   {flag: "local_cpus", value: "HOST_CPUS/2", wantErr: true},
+		"duration":                 {flag: "timeout", value: "01h30m", want: "1h30m"},
+		"duration milliseconds":    {flag: "timeout", value: "500ms", want: "500ms"},
+		"duration without unit":    {flag: "timeout", value: "30", wantErr: true},
+		"enum":                     {flag: "mode", value: "OPT", want: "opt"},
+		"enum invalid":             {flag: "mode", value: "release", wantErr: true},
+		"label":                    {flag: "toolchain", value: "@@//tools", want: "//tools:tools"},
+		"relative label":           {flag: "toolchain", value: ":tools", want: ":tools"},
+		"list":                     {flag: "tag_filters", value: "-manual,large", want: "-manual,large"},
+		"string":                   {flag: "untyped_string", value: "anything", want: "anything"},
+		"unknown flag":             {flag: "unknown", value: "anything", want: "anything"},
+		"starlark flag":            {flag: "//pkg:level", value: "+3", want: "3"},
+		"starlark flag invalid":    {flag: "//pkg:level", value: "high", wantErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := valueTypesFlagData.NormalizeValue(tc.flag, tc.value)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseNormalizesValues(t *testing.T) {
+	for name, tc := range map[string]struct {
+		input       string
+		wantEntries map[string]BazelFlagValues
+		wantTargets []string
+	}{
+		"boolean spellings": {
+			input: "build --bool_flag=yes --bool_flag=0 --nobool_flag --bool_flag",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {"bool_flag": []string{"true", "false", "false", "true"}},
+			},
+		},
+		"boolean spelling after a space is the flag's value": {
+			input: "build --bool_flag no //some:target",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {"bool_flag": []string{"false"}},
+			},
+			wantTargets: []string{"//some:target"},
+		},
+		"tri-state": {
+			input: "build --tristate_flag --notristate_flag --tristate_flag=auto --tristate_flag auto //some:target",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {"tristate_flag": []string{"yes", "no", "auto", "auto"}},
+			},
+			wantTargets: []string{"//some:target"},
+		},
+		"tri-state followed by a target": {
+			input: "build --tristate_flag //some:target",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {"tristate_flag": []string{"yes"}},
+			},
+			wantTargets: []string{"//some:target"},
+		},
+		"other types": {
+			input: "build --jobs 08 --local_cpus=HOST_CPUS*.50 --timeout=0090s --mode=DBG --toolchain=@//tools:cc --//pkg:level=007",
+			wantEntries: map[string]BazelFlagValues{
+				"build": {
+					"jobs":        []string{"8"},
+					"local_cpus":  []string{"HOST_CPUS*0.5"},
+					"timeout":     []string{"90s"},
+					"mode":        []string{"dbg"},
+					"toolchain":   []string{"//tools:cc"},
+					"//pkg:level": []string{"7"},
+				},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			parser := NewBazelRcParser("", valueTypesFlagData)
+			contents, err := parser.Parsefile(strings.NewReader(tc.input), "/sample/bazelrc")
+			require.NoError(t, err)
+			require.Equal(t, tc.wantEntries, contents.entries)
+
+			cmd, err := ParseCommandLineArgsAfterCommand(valueTypesFlagData, strings.Fields(tc.input)[1:])
+			require.NoError(t, err)
+			require.Equal(t, tc.wantEntries["build"], cmd.BazelFlags)
+			require.Equal(t, tc.wantTargets, cmd.Targets)
+		})
+	}
+}
+
+func TestInvalidFlagValues(t *testing.T) {
+	parser := NewBazelRcParser("", valueTypesFlagData)
+	_, err := parser.Parsefile(strings.NewReader("build --jobs=8\nbuild --mode=release"), "/sample/bazelrc")
+	var invalidErr *InvalidFlagValueError
+	require.True(t, errors.As(err, &invalidErr))
+	require.Equal(t, "mode", invalidErr.Flag)
+	require.Equal(t, "release", invalidErr.Value)
+	require.Equal(t, ValueTypeEnum, invalidErr.Type)
+	require.Equal(t, Position{File: "/sample/bazelrc", Line: 2, Column: 14, Offset: 28, ArgIndex: -1}, invalidErr.Position)
+	require.EqualError(t, err, `failed to process /sample/bazelrc on line 2, invalid value "release" for enum flag mode: expected one of fastbuild, dbg, opt`)
+
+	contents, diagnostics := parser.ParsefileCollectingDiagnostics(strings.NewReader("build --jobs=many --bool_flag\nbuild --jobs=4"), "/sample/bazelrc")
+	require.Len(t, diagnostics, 1)
+	require.Equal(t, map[string]BazelFlagValues{"build": {"jobs": []string{"4"}}}, contents.entries)
+
+	_, err = ParseCommandLineArgsAfterCommand(valueTypesFlagData, []string{"--timeout", "forever"})
+	require.True(t, errors.As(err, &invalidErr))
+	require.Equal(t, 1, invalidErr.ArgIndex)
+}
+
+// TestBooleanFlagsWithoutValueTypes covers flag data with only BooleanFlags, as produced by GetFlagDataFromBazel:
+// flags which may be given without a value aren't necessarily booleans (e.g. `--subcommands=pretty_print` or `--cache_test_results=auto`), so their values are kept as written.
+func TestBooleanFlagsWithoutValueTypes(t *testing.T) {
+	flagData := &FlagData{BooleanFlags: map[string]bool{"keep_going": true, "subcommands": true, "cache_test_results": true, "jobs": false}}
+	input := "build --keep_going=yes --jobs=010 --subcommands=pretty_print --cache_test_results=auto --nokeep_going --subcommands"
+	want := BazelFlagValues{
+		"keep_going":         []string{"yes", "false"},
+		"jobs":               []string{"010"},
+		"subcommands":        []string{"pretty_print", "true"},
+		"cache_test_results": []string{"auto"},
+	}
+
+	contents, err := NewBazelRcParser("", flagData).Parsefile(strings.NewReader(input), "/sample/bazelrc")
+	require.NoError(t, err)
+	require.Equal(t, map[string]BazelFlagValues{"build": want}, contents.entries)
+
+	cmd, err := ParseCommandLineArgsAfterCommand(flagData, strings.Fields(input)[1:])
+	require.NoError(t, err)
+	require.Equal(t, want, cmd.BazelFlags)
+
+	// Values spelled as booleans are still compared as booleans.
+	require.Empty(t, CompareFlagValues(flagData, BazelFlagValues{"keep_going": {"yes"}, "subcommands": {"pretty_print"}}, BazelFlagValues{"keep_going": {"1"}, "subcommands": {"pretty_print"}}))
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bazel-contrib/bazelrc-parser-go/bazelrc"
+)
+
+// runCacheCompat lists the differences between the options of two invocations which stop them sharing remote cache entries, e.g. between developers' and CI's invocations.
+// It fails if there are any such differences.
+func runCacheCompat(args []string, stdout io.Writer) error {
+	const usage = "usage: bazelrc cache-compat [flags], describing each invocation with either -<side>_bep or -<side>_args"
+	flags := flag.NewFlagSet("cache-compat", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	var resolverFlags resolverFlags
+	resolverFlags.register(flags)
+	a := invocationFlags{name: "a"}
+	a.register(flags)
+	b := invocationFlags{name: "b"}
+	b.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New(usage)
+	}
+	flagData, err := resolverFlags.flagData(a.workspace)
+	if err != nil {
+		return err
+	}
+	aValues, err := a.values(&resolverFlags, flagData)
+	if err != nil {
+		return err
+	}
+	bValues, err := b.values(&resolverFlags, flagData)
+	if err != nil {
+		return err
+	}
+
+	compatibility := bazelrc.CheckRemoteCacheCompatibility(flagData, aValues, bValues)
+	printDifferences := func(heading string, differences []bazelrc.ClassifiedDifference) {
+		if len(differences) == 0 {
+			return
+		}
+		fmt.Fprintln(stdout, heading)
+		for _, difference := range differences {
+			fmt.Fprintf(stdout, "  --%s (%s): a %q, b %q\n", difference.Flag, difference.Impact, difference.Want, difference.Got)
+		}
+	}
+	printDifferences("Options which change action keys:", compatibility.Incompatible)
+	printDifferences("Options whose effect on action keys is unknown:", compatibility.Unknown)
+	if !compatibility.Compatible() {
+		return errors.New("the invocations won't share remote cache entries")
+	}
+	return nil
+}
+
+// invocationFlags are the flags describing one of the invocations compared by cache-compat, either as a Build Event Protocol file, or as a bazelrc file and command line.
+type invocationFlags struct {
+	// name prefixes the names of the flags, e.g. "a" for `-a_bep`.
+	name      string
+	bep       string
+	workspace string
+	bazelrc   string
+	args      string
+}
+
+func (f *invocationFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&f.bep, f.name+"_bep", "", "a Build Event Protocol file written by invocation "+f.name+", from --build_event_json_file (if it ends in .json) or --build_event_binary_file")
+	flags.StringVar(&f.workspace, f.name+"_workspace", ".", "the workspace directory of invocation "+f.name)
+	flags.StringVar(&f.bazelrc, f.name+"_bazelrc", "", "the bazelrc file read by invocation "+f.name+" (default: the .bazelrc file in its workspace directory, if there is one)")
+	flags.StringVar(&f.args, f.name+"_args", "", "the space-separated arguments of invocation "+f.name+" after the bazel binary, e.g. `build --config=ci //...`")
+}
+
+// values returns the effective values of the invocation's command options.
+func (f *invocationFlags) values(resolverFlags *resolverFlags, flagData *bazelrc.FlagData) (bazelrc.BazelFlagValues, error) {
+	if (f.bep == "") == (f.args == "") {
+		return nil, fmt.Errorf("exactly one of -%s_bep and -%s_args must be given", f.name, f.name)
+	}
+	if f.bep != "" {
+		options, err := bazelrc.ReadBuildEventFile(f.bep)
+		if err != nil {
+			return nil, err
+		}
+		return options.EffectiveFlagValues(flagData)
+	}
+	contents, err := parseWorkspaceBazelrc(f.workspace, f.bazelrc, flagData)
+	if err != nil {
+		return nil, err
+	}
+	commandLine, err := bazelrc.BuildStructuredCommandLine(contents, flagData, append([]string{"bazel"}, strings.Fields(f.args)...), resolverFlags.resolverOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	return bazelrc.FlagValuesFromCommandLine(commandLine.Canonical, "command options"), nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// runCanonicalize prints the canonical options of an invocation as JSON, in the form of bazelrc.CanonicalFlags.
+func runCanonicalize(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("canonicalize", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: bazelrc canonicalize [flags] <command> [args...]")
+		flags.PrintDefaults()
+	}
+	var inputs inputFlags
+	inputs.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("usage: bazelrc canonicalize [flags] <command> [args...]")
+	}
+	resolver, err := inputs.resolver()
+	if err != nil {
+		return err
+	}
+	canonical, err := resolver.Canonicalize(flags.Arg(0), flags.Args()[1:])
+	if err != nil {
+		return err
+	}
+	encoded, err := canonical.JSON()
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(append(encoded, '\n'))
+	return err
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/bazel-contrib/bazelrc-parser-go/bazelrc"
+)
+
+// runDiff prints how the effective options of invocations differ between the bazelrc files of two workspaces, e.g. two checkouts of a repository.
+func runDiff(args []string, stdout io.Writer) error {
+	const usage = "usage: bazelrc diff [flags] <before workspace> <after workspace>"
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	var resolverFlags resolverFlags
+	resolverFlags.register(flags)
+	var invocationFlags stringListFlag
+	flags.Var(&invocationFlags, "invocation", "an invocation to compare, e.g. `test --config=ci`; may be given multiple times (default: every command and config with a section in either bazelrc file)")
+	bazelrcPath := flags.String("bazelrc", ".bazelrc", "the path of the bazelrc file within each workspace")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return errors.New(usage)
+	}
+	flagData, err := resolverFlags.flagData(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	var contents []*bazelrc.BazelrcContents
+	for _, workspace := range flags.Args() {
+		// Files are read relative to each workspace, so that the same files have the same paths on both sides.
+		fsys := os.DirFS(workspace)
+		parser := bazelrc.NewBazelRcParser(".", flagData, bazelrc.WithFS(fsys))
+		workspaceContents, err := parseOptionalBazelrc(parser, *bazelrcPath, func(path string) (fs.FileInfo, error) { return fs.Stat(fsys, path) })
+		if err != nil {
+			return fmt.Errorf("failed to read bazelrc of %s: %w", workspace, err)
+		}
+		contents = append(contents, workspaceContents)
+	}
+
+	invocations := bazelrc.Invocations(contents...)
+	if len(invocationFlags) != 0 {
+		invocations = nil
+		for _, invocationFlag := range invocationFlags {
+			invocation, err := parseInvocation(invocationFlag)
+			if err != nil {
+				return err
+			}
+			invocations = append(invocations, invocation)
+		}
+	}
+	diffs := bazelrc.DiffInvocations(resolverFlags.resolver(contents[0], flagData), resolverFlags.resolver(contents[1], flagData), invocations)
+	for _, diff := range diffs {
+		fmt.Fprintf(stdout, "%s:\n", diff.Invocation)
+		if diff.BeforeErr != nil {
+			fmt.Fprintf(stdout, "  before: %v\n", diff.BeforeErr)
+		}
+		if diff.AfterErr != nil {
+			fmt.Fprintf(stdout, "  after: %v\n", diff.AfterErr)
+		}
+		for _, change := range diff.Changes {
+			fmt.Fprintf(stdout, "  %s --%s: %s -> %s\n", change.Kind, change.Flag, describeValues(change.Before, change.BeforeSources), describeValues(change.After, change.AfterSources))
+		}
+	}
+	return nil
+}
+
+// parseInvocation parses an invocation given as e.g. `test --config=ci --config=remote`.
+func parseInvocation(invocation string) (bazelrc.Invocation, error) {
+	fields := strings.Fields(invocation)
+	if len(fields) == 0 {
+		return bazelrc.Invocation{}, errors.New("-invocation must not be empty")
+	}
+	parsed := bazelrc.Invocation{Command: fields[0]}
+	for _, field := range fields[1:] {
+		config, ok := strings.CutPrefix(field, "--config=")
+		if !ok {
+			return bazelrc.Invocation{}, fmt.Errorf("failed to parse -invocation %q: expected only --config options after the command, got %q", invocation, field)
+		}
+		parsed.Configs = append(parsed.Configs, config)
+	}
+	return parsed, nil
+}
+
+// describeValues describes the effective values of a flag and where they were set, e.g. `"10" (.bazelrc:2:7 via --config=ci)`.
+func describeValues(values []string, sources []bazelrc.ResolvedOption) string {
+	if len(values) == 0 {
+		return "unset"
+	}
+	described := make([]string, 0, len(values))
+	for i, value := range values {
+		source := sources[i].Span().Start.String()
+		for _, via := range sources[i].Via {
+			source += fmt.Sprintf(" via --%s=%s", via.Name, via.Value)
+		}
+		described = append(described, fmt.Sprintf("%q (%s)", value, source))
+	}
+	return strings.Join(described, ", ")
+}
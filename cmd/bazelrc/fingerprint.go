@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/bazel-contrib/bazelrc-parser-go/bazelrc"
+)
+
+// runFingerprint prints the fingerprint of the options of an invocation which affect its outputs as JSON, in the form of bazelrc.ConfigurationFingerprint.
+func runFingerprint(args []string, stdout io.Writer) error {
+	const usage = "usage: bazelrc fingerprint [flags] <command> [args...], or bazelrc fingerprint [flags] -bep <file>"
+	flags := flag.NewFlagSet("fingerprint", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	var inputs inputFlags
+	inputs.register(flags)
+	bep := flags.String("bep", "", "a Build Event Protocol file written by the invocation, from --build_event_json_file (if it ends in .json) or --build_event_binary_file, instead of a command line")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if (*bep == "") == (flags.NArg() == 0) {
+		return errors.New(usage)
+	}
+	flagData, err := inputs.flagData(inputs.workspace)
+	if err != nil {
+		return err
+	}
+
+	var values bazelrc.BazelFlagValues
+	if *bep != "" {
+		options, err := bazelrc.ReadBuildEventFile(*bep)
+		if err != nil {
+			return err
+		}
+		if values, err = options.EffectiveFlagValues(flagData); err != nil {
+			return err
+		}
+	} else {
+		contents, err := parseWorkspaceBazelrc(inputs.workspace, inputs.bazelrc, flagData)
+		if err != nil {
+			return err
+		}
+		parsed, err := bazelrc.ParseCommandLineArgsAfterCommand(flagData, flags.Args()[1:])
+		if err != nil {
+			return err
+		}
+		resolved, err := inputs.resolverFlags.resolver(contents, flagData).Resolve(flags.Arg(0), parsed.Options)
+		if err != nil {
+			return err
+		}
+		values = resolved.Values()
+	}
+
+	fingerprint, err := bazelrc.FingerprintConfiguration(flagData, values)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.MarshalIndent(fingerprint, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(append(encoded, '\n'))
+	return err
+}
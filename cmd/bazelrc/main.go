@@ -0,0 +1,162 @@
+// Command bazelrc inspects bazelrc files and Bazel command lines without invoking Bazel.
+//
+// Usage:
+//
+//	bazelrc <subcommand> [flags] [args...]
+//
+// Subcommands:
+//
+//	cache-compat    list the option differences which stop two invocations sharing remote cache entries
+//	canonicalize    print the canonical options of an invocation as JSON
+//	diff            compare the effective options of invocations between two workspaces' bazelrc files
+//	fingerprint     print a fingerprint of the options of an invocation which affect its outputs
+//	unused-configs  list the config definitions which are unreachable, for commands no one runs, or shadowed
+//
+// Run `bazelrc <subcommand> -help` for the flags of each subcommand.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bazel-contrib/bazelrc-parser-go/bazelrc"
+)
+
+// subcommands maps the name of each subcommand to its implementation, which is passed the arguments following the name.
+var subcommands = map[string]func(args []string, stdout io.Writer) error{
+	"canonicalize":   runCanonicalize,
+	"cache-compat":   runCacheCompat,
+	"diff":           runDiff,
+	"fingerprint":    runFingerprint,
+	"unused-configs": runUnusedConfigs,
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "bazelrc: %v\n", err)
+		}
+		os.Exit(2)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	var names []string
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(args) == 0 {
+		return fmt.Errorf("usage: bazelrc <subcommand> [flags] [args...], where subcommand is one of: %s", strings.Join(names, ", "))
+	}
+	subcommand, ok := subcommands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown subcommand %q, expected one of: %s", args[0], strings.Join(names, ", "))
+	}
+	return subcommand(args[1:], stdout)
+}
+
+// stringListFlag is a flag which may be given multiple times, accumulating values.
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// resolverFlags are the flags shared by every subcommand, describing Bazel's flags and how options are resolved.
+type resolverFlags struct {
+	helpOutputs    stringListFlag
+	bazel          string
+	platformConfig string
+}
+
+func (f *resolverFlags) register(flags *flag.FlagSet) {
+	flags.Var(&f.helpOutputs, "help_output", "a file containing the output of `bazel help <command> --long`, describing the flags of a command; may be given multiple times")
+	flags.StringVar(&f.bazel, "bazel", "", "a bazel binary to run to describe flags, instead of -help_output")
+	flags.StringVar(&f.platformConfig, "platform_config", "", "the config applied by --enable_platform_specific_config (default: the config for the current OS)")
+}
+
+// flagData loads flag data from -bazel (run in directory) or -help_output.
+// If neither is given, nothing is known about flags, so options are parsed by their syntax alone.
+func (f *resolverFlags) flagData(directory string) (*bazelrc.FlagData, error) {
+	if f.bazel != "" {
+		if len(f.helpOutputs) != 0 {
+			return nil, errors.New("-bazel and -help_output can't both be given")
+		}
+		command := exec.Command(f.bazel)
+		command.Dir = directory
+		command.Stderr = os.Stderr
+		return bazelrc.GetFlagDataFromBazel(command)
+	}
+	return bazelrc.GetFlagDataFromHelpOutputFiles(f.helpOutputs...)
+}
+
+// resolverOptions returns the options of Resolvers selected by the flags.
+func (f *resolverFlags) resolverOptions() []bazelrc.ResolverOption {
+	var options []bazelrc.ResolverOption
+	if f.platformConfig != "" {
+		options = append(options, bazelrc.WithPlatformConfig(f.platformConfig))
+	}
+	return options
+}
+
+// resolver makes a Resolver of the options in contents.
+func (f *resolverFlags) resolver(contents *bazelrc.BazelrcContents, flagData *bazelrc.FlagData) *bazelrc.Resolver {
+	return bazelrc.NewResolver(contents, flagData, f.resolverOptions()...)
+}
+
+// inputFlags are the flags of subcommands which read the bazelrc file of a single workspace.
+type inputFlags struct {
+	resolverFlags
+	workspace string
+	bazelrc   string
+}
+
+func (f *inputFlags) register(flags *flag.FlagSet) {
+	f.resolverFlags.register(flags)
+	flags.StringVar(&f.workspace, "workspace", ".", "the workspace directory, which `%workspace%` in imports refers to")
+	flags.StringVar(&f.bazelrc, "bazelrc", "", "the bazelrc file to read (default: the .bazelrc file in the workspace directory, if there is one)")
+}
+
+// resolver loads flag data and the bazelrc file, and makes a Resolver of their options.
+func (f *inputFlags) resolver() (*bazelrc.Resolver, error) {
+	flagData, err := f.flagData(f.workspace)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := parseWorkspaceBazelrc(f.workspace, f.bazelrc, flagData)
+	if err != nil {
+		return nil, err
+	}
+	return f.resolverFlags.resolver(contents, flagData), nil
+}
+
+// parseWorkspaceBazelrc parses the bazelrc file at path, or if path is empty, the .bazelrc file in workspace if there is one.
+func parseWorkspaceBazelrc(workspace string, path string, flagData *bazelrc.FlagData) (*bazelrc.BazelrcContents, error) {
+	parser := bazelrc.NewBazelRcParser(workspace, flagData)
+	if path != "" {
+		return parser.ParsePath(path)
+	}
+	return parseOptionalBazelrc(parser, filepath.Join(workspace, ".bazelrc"), os.Stat)
+}
+
+// parseOptionalBazelrc parses the bazelrc file at path, or returns empty contents if stat reports that there's no such file.
+func parseOptionalBazelrc(parser *bazelrc.BazelRcParser, path string, stat func(string) (fs.FileInfo, error)) (*bazelrc.BazelrcContents, error) {
+	if _, err := stat(path); errors.Is(err, fs.ErrNotExist) {
+		return parser.Parsefile(strings.NewReader(""), path)
+	}
+	return parser.ParsePath(path)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	var stdout bytes.Buffer
+	require.EqualError(t, run(nil, &stdout), "usage: bazelrc <subcommand> [flags] [args...], where subcommand is one of: cache-compat, canonicalize, diff, fingerprint, unused-configs")
+	require.EqualError(t, run([]string{"frobnicate"}, &stdout), `unknown subcommand "frobnicate", expected one of: cache-compat, canonicalize, diff, fingerprint, unused-configs`)
+}
+
+func TestCanonicalize(t *testing.T) {
+	workspace, err := os.MkdirTemp("", "workspace")
+	require.NoError(t, err)
+	defer os.RemoveAll(workspace)
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, ".bazelrc"), []byte("build --jobs=10\nbuild:ci --keep_going\n"), 0o644))
+	helpOutput := filepath.Join(workspace, "help.txt")
+	require.NoError(t, os.WriteFile(helpOutput, []byte(`Options that control build execution:
+  --[no]keep_going [-k] (a boolean; default: "false")
+    Continue as much as possible after an error.
+`), 0o644))
+
+	for name, tc := range map[string]struct {
+		args    []string
+		want    string
+		wantErr string
+	}{
+		"workspace bazelrc": {
+			args: []string{"-workspace", workspace, "-help_output", helpOutput, "test", "--config=ci", "//foo/...", "--jobs=5"},
+			want: `{"command": "test", "options": ["--keep_going=1", "--jobs=5"], "targets": ["//foo/..."], "executableArgs": []}`,
+		},
+		"missing default bazelrc": {
+			args: []string{"-workspace", filepath.Join(workspace, "missing"), "build", "--jobs=5"},
+			want: `{"command": "build", "options": ["--jobs=5"], "targets": [], "executableArgs": []}`,
+		},
+		"missing explicit bazelrc": {
+			args:    []string{"-bazelrc", filepath.Join(workspace, "missing.bazelrc"), "build"},
+			wantErr: "failed to process " + filepath.Join(workspace, "missing.bazelrc") + ", unable to open file: open " + filepath.Join(workspace, "missing.bazelrc") + ": no such file or directory",
+		},
+		"missing command": {
+			args:    []string{"-workspace", workspace},
+			wantErr: "usage: bazelrc canonicalize [flags] <command> [args...]",
+		},
+		"undefined config": {
+			args:    []string{"-workspace", workspace, "-help_output", helpOutput, "build", "--config=missing"},
+			wantErr: "config value 'missing' is not defined in any .rc file",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(append([]string{"canonicalize"}, tc.args...), &stdout)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tc.want, stdout.String())
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	testDir, err := os.MkdirTemp("", "diff")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	before := filepath.Join(testDir, "before")
+	after := filepath.Join(testDir, "after")
+	require.NoError(t, os.MkdirAll(filepath.Join(after, "tools"), 0o755))
+	require.NoError(t, os.Mkdir(before, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(before, ".bazelrc"), []byte("build --jobs=10\nbuild:ci --jobs=20 --color=yes\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(after, ".bazelrc"), []byte("build --jobs=10\nimport %workspace%/tools/ci.bazelrc\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(after, "tools", "ci.bazelrc"), []byte("build:ci --jobs=30 --test_output=errors\n"), 0o644))
+
+	for name, tc := range map[string]struct {
+		args    []string
+		want    string
+		wantErr string
+	}{
+		"every section": {
+			args: []string{before, after},
+			want: `build --config=ci:
+  removed --color: "yes" (.bazelrc:2:20 via --config=ci) -> unset
+  changed --jobs: "20" (.bazelrc:2:10 via --config=ci) -> "30" (tools/ci.bazelrc:1:10 via --config=ci)
+  added --test_output: unset -> "errors" (tools/ci.bazelrc:1:20 via --config=ci)
+`,
+		},
+		"selected invocations": {
+			args: []string{"-invocation", "build", "-invocation", "test --config=missing", before, after},
+			want: "",
+		},
+		"missing workspace bazelrc": {
+			args: []string{"-invocation", "build --config=ci", filepath.Join(testDir, "missing"), after},
+			want: `build --config=ci:
+  before: config value 'ci' is not defined in any .rc file
+`,
+		},
+		"invalid invocation": {
+			args:    []string{"-invocation", "build --jobs=1", before, after},
+			wantErr: `failed to parse -invocation "build --jobs=1": expected only --config options after the command, got "--jobs=1"`,
+		},
+		"missing workspace": {
+			args:    []string{before},
+			wantErr: "usage: bazelrc diff [flags] <before workspace> <after workspace>",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(append([]string{"diff"}, tc.args...), &stdout)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, stdout.String())
+		})
+	}
+}
+
+func TestCacheCompat(t *testing.T) {
+	workspace, err := os.MkdirTemp("", "workspace")
+	require.NoError(t, err)
+	defer os.RemoveAll(workspace)
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, ".bazelrc"), []byte("build --jobs=10\nbuild:ci --jobs=100 --disk_cache= --remote_header=x\nbuild:opt --compilation_mode=opt\n"), 0o644))
+	helpOutput := filepath.Join(workspace, "help.txt")
+	require.NoError(t, os.WriteFile(helpOutput, []byte(`Options that control build execution:
+  --jobs [-j] (an integer; default: "auto")
+    Tags: host_machine_resource_optimizations, execution
+  --compilation_mode [-c] (fastbuild, dbg or opt; default: "fastbuild")
+    Tags: affects_outputs, action_command_lines
+`), 0o644))
+	bep := filepath.Join(workspace, "build_events.json")
+	require.NoError(t, os.WriteFile(bep, []byte(`{"optionsParsed": {"cmdLine": ["--jobs=4", "--compilation_mode=opt"]}}`+"\n"), 0o644))
+
+	for name, tc := range map[string]struct {
+		args    []string
+		want    string
+		wantErr string
+	}{
+		"compatible": {
+			args: []string{"-a_args", "build //...", "-b_args", "build --config=ci //..."},
+			want: `Options whose effect on action keys is unknown:
+  --remote_header (unknown): a [], b ["x"]
+`,
+		},
+		"incompatible": {
+			args: []string{"-a_args", "build --config=ci //...", "-b_bep", bep},
+			want: `Options which change action keys:
+  --compilation_mode (changes action outputs): a [], b ["opt"]
+Options whose effect on action keys is unknown:
+  --remote_header (unknown): a ["x"], b []
+`,
+			wantErr: "the invocations won't share remote cache entries",
+		},
+		"same as build events": {
+			args: []string{"-a_args", "build --config=opt", "-b_bep", bep},
+		},
+		"missing invocation": {
+			args:    []string{"-a_args", "build"},
+			wantErr: "exactly one of -b_bep and -b_args must be given",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := run(append([]string{"cache-compat", "-help_output", helpOutput, "-a_workspace", workspace, "-b_workspace", workspace}, tc.args...), &stdout)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.want, stdout.String())
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	workspace, err := os.MkdirTemp("", "workspace")
+	require.NoError(t, err)
+	defer os.RemoveAll(workspace)
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, ".bazelrc"), []byte("build --jobs=10 --compilation_mode=dbg\nbuild:opt --compilation_mode=opt\n"), 0o644))
+	helpOutput := filepath.Join(workspace, "help.txt")
+	require.NoError(t, os.WriteFile(helpOutput, []byte(`Options that control build execution:
+  --jobs [-j] (an integer; default: "auto")
+    Tags: host_machine_resource_optimizations, execution
+  --compilation_mode [-c] (fastbuild, dbg or opt; default: "fastbuild")
+    Tags: affects_outputs, action_command_lines
+`), 0o644))
+	bep := filepath.Join(workspace, "build_events.json")
+	require.NoError(t, os.WriteFile(bep, []byte(`{"optionsParsed": {"cmdLine": ["--jobs=4", "--compilation_mode=opt"]}}`+"\n"), 0o644))
+
+	fingerprint := func(args ...string) string {
+		var stdout bytes.Buffer
+		require.NoError(t, run(append([]string{"fingerprint", "-help_output", helpOutput, "-workspace", workspace}, args...), &stdout))
+		return stdout.String()
+	}
+	fromCommandLine := fingerprint("build", "--config=opt", "-j", "5", "//...")
+	require.Contains(t, fromCommandLine, `"--compilation_mode=opt"`)
+	require.Equal(t, fromCommandLine, fingerprint("-bep", bep))
+	require.NotEqual(t, fromCommandLine, fingerprint("build"))
+
+	var stdout bytes.Buffer
+	require.EqualError(t, run([]string{"fingerprint", "-bep", bep, "build"}, &stdout), "usage: bazelrc fingerprint [flags] <command> [args...], or bazelrc fingerprint [flags] -bep <file>")
+
+	untaggedHelpOutput := filepath.Join(workspace, "untagged_help.txt")
+	require.NoError(t, os.WriteFile(untaggedHelpOutput, []byte("  --jobs [-j] (an integer; default: \"auto\")\n  --compilation_mode [-c] (fastbuild, dbg or opt; default: \"fastbuild\")\n"), 0o644))
+	require.EqualError(t, run([]string{"fingerprint", "-help_output", untaggedHelpOutput, "-workspace", workspace, "build"}, &stdout), "failed to fingerprint configuration: no effect tags are known, so the options which affect outputs can't be identified")
+}
+
+func TestUnusedConfigs(t *testing.T) {
+	workspace, err := os.MkdirTemp("", "workspace")
+	require.NoError(t, err)
+	defer os.RemoveAll(workspace)
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, ".bazelrc"), []byte(`build --config=base
+build:base --jobs=10
+build:ci --keep_going
+coverage:cov --keep_going
+build:old --jobs=1
+`), 0o644))
+	helpOutput := filepath.Join(workspace, "help.txt")
+	require.NoError(t, os.WriteFile(helpOutput, []byte(`Options that control build execution:
+  --jobs [-j] (an integer; default: "auto")
+  --[no]keep_going [-k] (a boolean; default: "false")
+`), 0o644))
+	invocationsFile := filepath.Join(workspace, "invocations.txt")
+	require.NoError(t, os.WriteFile(invocationsFile, []byte("# From CI.\nbazel test --config=ci //...\n\nbazel build --config=cov //foo\n"), 0o644))
+
+	var stdout bytes.Buffer
+	require.EqualError(t, run([]string{"unused-configs", "-help_output", helpOutput, "-workspace", workspace, "-invocations_file", invocationsFile}, &stdout), "found unused config definitions")
+	require.Equal(t, `Unreachable configs:
+  `+workspace+`/.bazelrc:5:1: build:old
+Config definitions for commands which aren't run:
+  `+workspace+`/.bazelrc:4:1: coverage:cov
+`, stdout.String())
+
+	stdout.Reset()
+	require.NoError(t, run([]string{"unused-configs", "-help_output", helpOutput, "-workspace", workspace, "-invocation", "coverage --config=ci", "-invocation", "build --config=cov", "-invocation", "build --config=old"}, &stdout))
+	require.Empty(t, stdout.String())
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bazel-contrib/bazelrc-parser-go/bazelrc"
+)
+
+// runUnusedConfigs prints the config definitions of a bazelrc file which are unreachable, for commands no one runs, or shadowed by later definitions.
+func runUnusedConfigs(args []string, stdout io.Writer) error {
+	const usage = "usage: bazelrc unused-configs [flags]"
+	flags := flag.NewFlagSet("unused-configs", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	var inputs inputFlags
+	inputs.register(flags)
+	var invocationFlags stringListFlag
+	flags.Var(&invocationFlags, "invocation", "a known invocation, e.g. `test --config=ci`; may be given multiple times")
+	invocationsFile := flags.String("invocations_file", "", "a file of known Bazel command lines, one per line (e.g. `bazel test --config=ci //...`), such as those run by CI scripts")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New(usage)
+	}
+	flagData, err := inputs.flagData(inputs.workspace)
+	if err != nil {
+		return err
+	}
+	contents, err := parseWorkspaceBazelrc(inputs.workspace, inputs.bazelrc, flagData)
+	if err != nil {
+		return err
+	}
+
+	var invocations []bazelrc.Invocation
+	for _, invocationFlag := range invocationFlags {
+		invocation, err := parseInvocation(invocationFlag)
+		if err != nil {
+			return err
+		}
+		invocations = append(invocations, invocation)
+	}
+	if *invocationsFile != "" {
+		fileInvocations, err := readInvocationsFile(*invocationsFile, flagData)
+		if err != nil {
+			return err
+		}
+		invocations = append(invocations, fileInvocations...)
+	}
+
+	configUsage := bazelrc.AnalyzeConfigUsage(contents, flagData, invocations)
+	printDefinitions := func(heading string, definitions []bazelrc.ConfigDefinition) {
+		if len(definitions) == 0 {
+			return
+		}
+		fmt.Fprintln(stdout, heading)
+		for _, definition := range definitions {
+			fmt.Fprintf(stdout, "  %s\n", definition)
+		}
+	}
+	printDefinitions("Unreachable configs:", configUsage.Unreachable)
+	printDefinitions("Config definitions for commands which aren't run:", configUsage.UnrunCommands)
+	printDefinitions("Shadowed by later definitions:", configUsage.Shadowed)
+	if len(configUsage.Unreachable) != 0 || len(configUsage.UnrunCommands) != 0 || len(configUsage.Shadowed) != 0 {
+		return errors.New("found unused config definitions")
+	}
+	return nil
+}
+
+// readInvocationsFile parses the Bazel command line on each line of the file at path, ignoring blank lines and `#` comments.
+// Arguments are separated by whitespace, without any shell quoting.
+func readInvocationsFile(path string, flagData *bazelrc.FlagData) ([]bazelrc.Invocation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var invocations []bazelrc.Invocation
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		argv := strings.Fields(scanner.Text())
+		if len(argv) == 0 || strings.HasPrefix(argv[0], "#") {
+			continue
+		}
+		invocation, err := bazelrc.ParseInvocation(flagData, argv)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+		invocations = append(invocations, invocation)
+	}
+	return invocations, scanner.Err()
+}
@@ -4,7 +4,6 @@ go 1.22.0
 
 require (
 	github.com/bazelbuild/rules_go v0.47.0
-	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/exp v0.0.0-20240416160154-fe59bbe5cc7f
 	google.golang.org/protobuf v1.31.0